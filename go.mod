@@ -1,5 +1,7 @@
 module github.com/livechat/gokit
 
+go 1.20
+
 require (
 	github.com/Rican7/conjson v0.1.0
 	github.com/golang/protobuf v1.3.2
@@ -8,3 +10,10 @@ require (
 	github.com/gorilla/websocket v1.4.0
 	google.golang.org/grpc v1.24.0
 )
+
+require (
+	golang.org/x/net v0.0.0-20190311183353-d8887717615a // indirect
+	golang.org/x/sys v0.0.0-20190215142949-d0b11bdaac8a // indirect
+	golang.org/x/text v0.3.0 // indirect
+	google.golang.org/genproto v0.0.0-20180817151627-c66870c02cf8 // indirect
+)
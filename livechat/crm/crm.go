@@ -13,6 +13,7 @@ type API struct {
 
 func New(host, token string) *API {
 	c := client.NewCaller(http.DefaultClient,
+		client.Dedupe(),
 		client.ResponseError(nil),
 		client.JSONRequest(),
 		client.JSONResponse(),
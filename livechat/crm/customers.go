@@ -1,7 +1,7 @@
 package crm
 
 import (
-	"fmt"
+	"net/url"
 	"strconv"
 
 	"github.com/livechat/gokit/web/client"
@@ -62,7 +62,8 @@ type Customers struct {
 func (s Customers) Search(query string) (map[string]interface{}, error) {
 	var r map[string]interface{}
 	return r, s.http.Call(client.Option{
-		URL:      fmt.Sprintf("%s/crm/customers/search?query=%s", s.host, query),
+		URL:      s.host + "/crm/customers/search",
+		Query:    url.Values{"query": {query}},
 		Method:   "GET",
 		Response: &r,
 	})
@@ -77,7 +78,8 @@ func (s Customers) Search(query string) (map[string]interface{}, error) {
 func (s Customers) ByLicense(number int) (Customer, error) {
 	var r Customer
 	return r, s.http.Call(client.Option{
-		URL:      fmt.Sprintf("%s/crm/customers/%d", s.host, number),
+		URL:      s.host + "/crm/customers/{license}",
+		Params:   map[string]string{"license": strconv.Itoa(number)},
 		Response: &r,
 	})
 }
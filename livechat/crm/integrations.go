@@ -2,8 +2,8 @@ package crm
 
 import (
 	"bytes"
-	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/livechat/gokit/web/client"
@@ -22,14 +22,16 @@ type Integration struct {
 
 func (i *integrations) Activate(application string, auth string) error {
 	return i.withOauth(auth).Call(client.Option{
-		URL:    fmt.Sprintf("%s/integrations?id=%s", i.url, application),
+		URL:    i.url + "/integrations",
+		Query:  url.Values{"id": {application}},
 		Method: http.MethodPost,
 	})
 }
 
 func (i *integrations) Deactivate(application string, token string) error {
 	return i.withOauth(token).Call(client.Option{
-		URL:    fmt.Sprintf("%s/integrations/%s", i.url, application),
+		URL:    i.url + "/integrations/{application}",
+		Params: map[string]string{"application": application},
 		Method: http.MethodDelete,
 	})
 }
@@ -38,7 +40,7 @@ func (i *integrations) All() ([]Integration, error) {
 	var response struct{ Integrations []Integration }
 
 	return response.Integrations, i.http.Call(client.Option{
-		URL:      fmt.Sprintf("%s/crm/integrations", i.url),
+		URL:      i.url + "/crm/integrations",
 		Method:   http.MethodGet,
 		Response: &response,
 	})
@@ -50,9 +52,9 @@ func (i *integrations) withOauth(token string) client.Caller {
 		region = "fra"
 	}
 
-	err := func(httpStatus int, body []byte) error {
-		if !bytes.Contains(body, []byte("Integration already exists!")) {
-			return fmt.Errorf("http(%d) with %s", httpStatus, string(body))
+	err := func(e *client.HTTPError) error {
+		if !bytes.Contains(e.Body, []byte("Integration already exists!")) {
+			return e
 		}
 		return nil
 	}
@@ -2,8 +2,8 @@ package gis
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
+	"net/url"
 
 	"github.com/livechat/gokit/web/client"
 )
@@ -43,6 +43,7 @@ func New(host, token string) *API {
 		host:  host,
 		token: token,
 		http: client.NewCaller(http.DefaultClient,
+			client.Dedupe(),
 			client.ResponseError(nil),
 			client.JSONRequest(),
 			client.JSONResponse(),
@@ -54,7 +55,9 @@ func New(host, token string) *API {
 func (a *API) License(number string) (Configuration, error) {
 	var r Configuration
 	return r, a.http.Call(client.Option{
-		URL:      fmt.Sprintf("%s/configuration/licence/%s?password=%s", a.host, number, a.token),
+		URL:      a.host + "/configuration/licence/{licence}",
+		Params:   map[string]string{"licence": number},
+		Query:    url.Values{"password": {a.token}},
 		Response: &r,
 	})
 }
@@ -62,7 +65,9 @@ func (a *API) License(number string) (Configuration, error) {
 func (a *API) Agent(mail string) (Configuration, error) {
 	var r Configuration
 	return r, a.http.Call(client.Option{
-		URL:      fmt.Sprintf("%s/configuration/operator/%s?password=%s", a.host, mail, a.token),
+		URL:      a.host + "/configuration/operator/{operator}",
+		Params:   map[string]string{"operator": mail},
+		Query:    url.Values{"password": {a.token}},
 		Response: &r,
 	})
 }
@@ -6,7 +6,6 @@ import (
 	"errors"
 	"fmt"
 	"strings"
-	"sync"
 
 	"github.com/livechat/gokit/livechat/sso/proto"
 	"github.com/livechat/gokit/web/client"
@@ -88,15 +87,23 @@ type API struct {
 	url      string
 	api      proto.SSOAPIClient
 	endpoint client.Caller
+	batch    client.Caller
 }
 
 func New(u string, a proto.SSOAPIClient, e client.Caller) *API {
-	return &API{u, a, e}
+	return &API{u, a, e, e}
+}
+
+// WithBatch returns copy of API which sends fan-out calls, as AddScopes, with b.
+func (s *API) WithBatch(b client.Caller) *API {
+	c := *s
+	c.batch = b
+	return &c
 }
 
 func (s *API) Info() (Info, error) {
 	in := client.Option{
-		URL:      fmt.Sprintf("%s/info", s.url),
+		URL:      s.url + "/info",
 		Response: &Info{}}
 
 	return *in.Response.(*Info), s.endpoint.Call(in)
@@ -105,7 +112,7 @@ func (s *API) Info() (Info, error) {
 func (s *API) All() ([]Client, error) {
 	o := map[string][]Client{}
 	r := client.Option{
-		URL:      fmt.Sprintf("%s/client", s.url),
+		URL:      s.url + "/client",
 		Response: &o}
 
 	if err := s.endpoint.Call(r); err != nil {
@@ -118,7 +125,8 @@ func (s *API) All() ([]Client, error) {
 func (s *API) One(id string) (Client, error) {
 	var c Client
 	err := s.endpoint.Call(client.Option{
-		URL:      s.url + fmt.Sprintf("/client/%s", id),
+		URL:      s.url + "/client/{id}",
+		Params:   map[string]string{"id": id},
 		Response: &c,
 		Method:   "GET",
 	})
@@ -130,7 +138,8 @@ func (s *API) Stats(id string) (Stats, error) {
 	var (
 		response Stats
 		err      = s.endpoint.Call(client.Option{
-			URL:      fmt.Sprintf("%s/client/%s/stats", s.url, id),
+			URL:      s.url + "/client/{id}/stats",
+			Params:   map[string]string{"id": id},
 			Response: &response,
 		})
 	)
@@ -194,14 +203,16 @@ func (s *API) Public(id string) error {
 
 func (s *API) RevokeAccess(id string) error {
 	return s.endpoint.Call(client.Option{
-		URL:    fmt.Sprintf("%s/client/%s/scopes/grant-access/license", s.url, id),
+		URL:    s.url + "/client/{id}/scopes/grant-access/license",
+		Params: map[string]string{"id": id},
 		Method: "DELETE",
 	})
 }
 
 func (s *API) Remove(id string) error {
 	return s.endpoint.Call(client.Option{
-		URL:      s.url + fmt.Sprintf("/client/%s", id),
+		URL:      s.url + "/client/{id}",
+		Params:   map[string]string{"id": id},
 		Response: nil,
 		Method:   "DELETE",
 	})
@@ -240,7 +251,8 @@ func (s *API) Store(c *Client) error {
 	removeScopes := c.Scopes != nil
 
 	if err := s.endpoint.Call(client.Option{
-		URL:      fmt.Sprintf("%s/client/%s", s.url, c.ID),
+		URL:      s.url + "/client/{id}",
+		Params:   map[string]string{"id": c.ID},
 		Method:   "PUT",
 		Request:  *c,
 		Response: &c}); err != nil {
@@ -264,7 +276,8 @@ func (s *API) Store(c *Client) error {
 
 func (s *API) Update(c *Client) error {
 	return s.endpoint.Call(client.Option{
-		URL:      fmt.Sprintf("%s/client/%s", s.url, c.ID),
+		URL:      s.url + "/client/{id}",
+		Params:   map[string]string{"id": c.ID},
 		Method:   "PUT",
 		Request:  *c,
 		Response: &c})
@@ -272,7 +285,8 @@ func (s *API) Update(c *Client) error {
 
 func (s *API) Delete(id string) error {
 	r := client.Option{
-		URL:      s.url + fmt.Sprintf("/client/%s", id),
+		URL:      s.url + "/client/{id}",
+		Params:   map[string]string{"id": id},
 		Response: &map[string]string{},
 		Method:   "DELETE"}
 
@@ -280,69 +294,44 @@ func (s *API) Delete(id string) error {
 }
 
 func (s *API) RemoveScopes(id string, scopes ...Scope) error {
-	var (
-		sentinel sync.WaitGroup
-		failed   sync.Map
-		errors   []Scope
-	)
-
-	for i := range scopes {
-		sentinel.Add(1)
-		go func(c Scope) {
-			defer sentinel.Done()
-			if err := s.endpoint.Call(client.Option{
-				URL:    fmt.Sprintf("%s/client/%s/scopes/%s", s.url, id, c.Name),
-				Method: "DELETE"}); err != nil {
-				failed.Store(c, err)
-			}
-		}(scopes[i])
-	}
-
-	sentinel.Wait()
-
-	failed.Range(func(key, value interface{}) bool {
-		errors = append(errors, key.(Scope))
-		return true
+	return s.scopes(scopes, func(c Scope) client.Option {
+		return client.Option{
+			URL:    s.url + "/client/{id}/scopes/{scope}",
+			Params: map[string]string{"id": id, "scope": c.Name},
+			Method: "DELETE"}
 	})
-
-	if len(errors) > 0 {
-		return fmt.Errorf("invalid scopes %+v", errors)
-	}
-
-	return nil
 }
 
 func (s *API) AddScopes(id string, scopes ...Scope) error {
-	var (
-		sentinel sync.WaitGroup
-		failed   sync.Map
-		err      []Scope
-	)
+	return s.scopes(scopes, func(c Scope) client.Option {
+		return client.Option{
+			URL:     s.url + "/client/{id}/scopes/{scope}",
+			Params:  map[string]string{"id": id, "scope": c.Name},
+			Request: fields{"required": c.Required},
+			Method:  "POST"}
+	})
+}
+
+// scopes sends call of every scope in batch, failed scopes are reported with their causes.
+func (s *API) scopes(scopes []Scope, call func(Scope) client.Option) error {
+	oo := make([]client.Option, len(scopes))
 	for i := range scopes {
-		sentinel.Add(1)
-		go func(scope Scope) {
-			defer sentinel.Done()
-			if err := s.endpoint.Call(client.Option{
-				URL:     fmt.Sprintf("%s/client/%s/scopes/%s", s.url, id, scope.Name),
-				Request: fields{"required": scope.Required},
-				Method:  "POST"}); err != nil {
-				failed.Store(scope, err)
-			}
-		}(scopes[i])
+		oo[i] = call(scopes[i])
 	}
 
-	sentinel.Wait()
-
-	failed.Range(func(key, value interface{}) bool {
-		err = append(err, key.(Scope))
-		return true
-	})
+	res, err := client.Batch(context.Background(), s.batch, client.BatchConfig{Concurrency: 4}, oo...)
+	if err == nil {
+		return nil
+	}
 
-	if len(err) > 0 {
-		return fmt.Errorf("invalid scopes %+v", err)
+	var failed []Scope
+	for i := range res {
+		if res[i].Err != nil {
+			failed = append(failed, scopes[i])
+		}
 	}
 
-	return nil
+	return fmt.Errorf("invalid scopes %+v: %w", failed, err)
 }
 
 func (s *API) Load(id string) (Client, error) {
@@ -375,7 +364,8 @@ type fields map[string]interface{}
 func (s *API) change(id string, f fields) error {
 	var res map[string]interface{}
 	return s.endpoint.Call(client.Option{
-		URL:      fmt.Sprintf("%s/client/%s", s.url, id),
+		URL:      s.url + "/client/{id}",
+		Params:   map[string]string{"id": id},
 		Method:   "PUT",
 		Request:  f,
 		Response: &res,
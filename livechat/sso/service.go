@@ -17,10 +17,27 @@ var ErrInsufficientScopes = errors.New("insufficient scopes")
 type API struct {
 	url    *url.URL
 	client proto.SSOAPIClient
+	limit  webclient.Middleware
 	HTTP   *HTTP
 }
 
-func New(host string) (*API, error) {
+// DefaultRateLimit is a conservative limit of fan-out calls (ie. AddScopes) sent
+// with single token, it keeps them at 10 requests per second with bursts of 5.
+// Other calls, as Info used by HTTP.Authenticate, are not limited.
+// LiveChat answers 429 with Retry-After when quota is exceeded, limiter adapts to
+// it, so the limit might be overridden with WithRateLimit for accounts with
+// different quota.
+var DefaultRateLimit = webclient.RateLimitConfig{Rate: 10, Burst: 5, Key: webclient.ByToken}
+
+// Option changes default configuration of API.
+type Option func(*API)
+
+// WithRateLimit overrides DefaultRateLimit of fan-out calls sent by clients.
+func WithRateLimit(c webclient.RateLimitConfig) Option {
+	return func(a *API) { a.limit = webclient.RateLimit(c) }
+}
+
+func New(host string, oo ...Option) (*API, error) {
 	a := API{}
 	u, err := url.Parse(host)
 	if err != nil {
@@ -34,33 +51,42 @@ func New(host string) (*API, error) {
 
 	a.url = u
 	a.client = proto.NewSSOAPIClient(c)
+	// limiter is shared between all clients, so fan-out calls such as
+	// AddScopes do not exceed LiveChat API quota for single token
+	a.limit = webclient.RateLimit(DefaultRateLimit)
 	a.HTTP = &HTTP{&a}
 
+	for _, o := range oo {
+		o(&a)
+	}
+
 	return &a, nil
 }
 
 func (s *API) Client(token string) *clients.API {
-	endpoint := webclient.NewCaller(webclient.Default,
+	mm := []webclient.Middleware{
 		webclient.ResponseError(httpError),
 		webclient.JSONResponse(),
 		webclient.JSONRequest(),
 		webclient.Authorization(token),
 		//webclient.Logging(log.Default.Print),
-	)
+	}
+
+	endpoint := webclient.NewCaller(webclient.Default, mm...)
+	batch := webclient.NewCaller(webclient.Default, append([]webclient.Middleware{s.limit}, mm...)...)
 
-	return clients.New(s.url.String(), s.client, endpoint)
+	return clients.New(s.url.String(), s.client, endpoint).WithBatch(batch)
 }
 
-func httpError(i int, body []byte) error {
-	switch i {
+func httpError(e *webclient.HTTPError) error {
+	switch e.StatusCode {
 	case http.StatusUnauthorized:
 		return clients.ErrWrongToken
 	case http.StatusUnprocessableEntity:
-		return fmt.Errorf("required correct values of: %s", body)
+		return fmt.Errorf("required correct values of: %s: %w", e.Body, e)
 	case http.StatusNotFound:
 		return clients.ErrNotFound
 	default:
-		return fmt.Errorf(string(body))
+		return e
 	}
-	return nil
 }
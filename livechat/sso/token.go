@@ -0,0 +1,82 @@
+package sso
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/livechat/gokit/livechat/sso/clients"
+	webclient "github.com/livechat/gokit/web/client"
+)
+
+// TokenSource keeps access token described by info and refreshes it with its
+// refresh token before expiry. Client credentials are required by token endpoint.
+func (s *API) TokenSource(info clients.Info, clientID, secret string) *webclient.RefreshingSource {
+	t := webclient.Token{
+		AccessToken:  info.AccessToken,
+		TokenType:    info.TokenType,
+		RefreshToken: info.RefreshToken,
+	}
+	if info.Expires > 0 {
+		t.Expiry = time.Now().Add(time.Duration(info.Expires) * time.Second)
+	}
+
+	return webclient.NewTokenSource(t, s.refresh(clientID, secret))
+}
+
+// ClientFrom works like Client, but token is taken from source, so it is refreshed
+// when it expires or when it is rejected by SSO.
+func (s *API) ClientFrom(src webclient.TokenSource) *clients.API {
+	endpoint := webclient.NewCaller(webclient.Default,
+		s.limit,
+		webclient.ResponseError(httpError),
+		webclient.JSONResponse(),
+		webclient.JSONRequest(),
+		webclient.OAuth2(src),
+	)
+
+	return clients.New(s.url.String(), s.client, endpoint)
+}
+
+func (s *API) refresh(clientID, secret string) webclient.RefreshFunc {
+	endpoint := webclient.NewCaller(webclient.Default,
+		webclient.ResponseError(httpError),
+		webclient.JSONResponse(),
+		webclient.FormRequest(),
+	)
+
+	return func(ctx context.Context, refreshToken string) (webclient.Token, error) {
+		var res struct {
+			AccessToken  string `json:"access_token"`
+			RefreshToken string `json:"refresh_token"`
+			TokenType    string `json:"token_type"`
+			Expires      int    `json:"expires_in"`
+		}
+
+		err := endpoint.CallContext(ctx, webclient.Option{
+			URL:    s.url.String() + "/token",
+			Method: "POST",
+			Request: url.Values{
+				"grant_type":    {"refresh_token"},
+				"refresh_token": {refreshToken},
+				"client_id":     {clientID},
+				"client_secret": {secret},
+			},
+			Response: &res,
+		})
+		if err != nil {
+			return webclient.Token{}, err
+		}
+
+		t := webclient.Token{
+			AccessToken:  res.AccessToken,
+			TokenType:    res.TokenType,
+			RefreshToken: res.RefreshToken,
+		}
+		if res.Expires > 0 {
+			t.Expiry = time.Now().Add(time.Duration(res.Expires) * time.Second)
+		}
+
+		return t, nil
+	}
+}
@@ -1,7 +1,6 @@
 package tags
 
 import (
-	"fmt"
 	"net/http"
 	"time"
 
@@ -43,7 +42,7 @@ func New(url, token string) *API {
 func (a *API) All() ([]Tag, error) {
 	var r []Tag
 	return r, a.http.Call(client.Option{
-		URL:      fmt.Sprintf("%s/tags", a.host),
+		URL:      a.host + "/tags",
 		Method:   "GET",
 		Response: &r,
 	})
@@ -0,0 +1,255 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Strategy chooses upstream of Balancer for request.
+type Strategy int
+
+const (
+	// RoundRobin sends requests to upstreams in turns.
+	RoundRobin Strategy = iota
+	// LeastInFlight sends request to upstream with the lowest number of requests in progress.
+	LeastInFlight
+	// Random sends request to randomly chosen upstream.
+	Random
+)
+
+// ErrNoUpstream is returned by Balancer created without hosts.
+var ErrNoUpstream = errors.New("balancer: no upstream hosts")
+
+// Balancer is an Endpoint which spreads requests across several base urls.
+// Request url is resolved against chosen upstream, so Option.URL should be a
+// path, ie. "/v2/tags". Upstream is ejected for a while after consecutive
+// failures, and idempotent requests are sent to the next upstream when they fail.
+type Balancer struct {
+	// Endpoint sends requests to upstreams (default Default).
+	Endpoint Endpoint
+	// MaxFailures is number of consecutive failures which ejects upstream (default 3).
+	MaxFailures int
+	// EjectFor is how long ejected upstream does not get requests (default 30s).
+	EjectFor time.Duration
+	// IsFailure tells if exchange failed (default transport error or 5xx status).
+	IsFailure func(*http.Response, error) bool
+
+	strategy  Strategy
+	upstreams []*upstream
+	next      uint32
+}
+
+type upstream struct {
+	base     *url.URL
+	inFlight int64
+
+	mu       sync.Mutex
+	failures int
+	ejected  time.Time
+}
+
+// Balanced creates Balancer of hosts, which are base urls as "https://api.livechatinc.com".
+// Hosts which can not be parsed are skipped.
+func Balanced(hosts []string, s Strategy) *Balancer {
+	b := &Balancer{
+		Endpoint:    Default,
+		MaxFailures: 3,
+		EjectFor:    30 * time.Second,
+		IsFailure: func(res *http.Response, err error) bool {
+			return err != nil || res.StatusCode >= 500
+		},
+		strategy: s,
+	}
+
+	for _, h := range hosts {
+		u, err := url.Parse(strings.TrimSuffix(h, "/"))
+		if err != nil || u.Host == "" {
+			continue
+		}
+		b.upstreams = append(b.upstreams, &upstream{base: u})
+	}
+
+	return b
+}
+
+func (b *Balancer) Do(r *http.Request) (*http.Response, error) {
+	order := b.order(time.Now())
+	if len(order) == 0 {
+		return nil, ErrNoUpstream
+	}
+
+	// request of the caller is not modified, every upstream gets its copy
+	target := *r.URL
+	req := r.Clone(r.Context())
+
+	for i, u := range order {
+		req.URL = u.resolve(&target)
+		req.Host = ""
+
+		atomic.AddInt64(&u.inFlight, 1)
+		res, err := b.Endpoint.Do(req)
+		atomic.AddInt64(&u.inFlight, -1)
+
+		failed := b.IsFailure(res, err)
+		b.report(u, failed)
+
+		last := i == len(order)-1
+		if !failed || last || !isIdempotent(r) || r.Context().Err() != nil {
+			return res, err
+		}
+
+		// request which body can not be replayed ends with failure of upstream
+		next, rerr := rewind(r)
+		if rerr != nil {
+			return res, err
+		}
+		drain(res)
+		req = next
+	}
+
+	return nil, ErrNoUpstream
+}
+
+// order returns upstreams in order of preference, ejected upstreams are used
+// only when all of them are ejected.
+func (b *Balancer) order(now time.Time) []*upstream {
+	n := len(b.upstreams)
+	if n == 0 {
+		return nil
+	}
+
+	var start int
+	if b.strategy == Random {
+		start = rand.Intn(n)
+	} else {
+		start = int(atomic.AddUint32(&b.next, 1)-1) % n
+	}
+
+	healthy := make([]*upstream, 0, n)
+	all := make([]*upstream, 0, n)
+	for i := 0; i < n; i++ {
+		u := b.upstreams[(start+i)%n]
+		all = append(all, u)
+		if u.healthy(now) {
+			healthy = append(healthy, u)
+		}
+	}
+
+	order := healthy
+	if len(order) == 0 {
+		order = all
+	}
+
+	if b.strategy == LeastInFlight {
+		sort.SliceStable(order, func(i, j int) bool {
+			return atomic.LoadInt64(&order[i].inFlight) < atomic.LoadInt64(&order[j].inFlight)
+		})
+	}
+
+	return order
+}
+
+func (b *Balancer) report(u *upstream, failed bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if !failed {
+		u.failures = 0
+		u.ejected = time.Time{}
+		return
+	}
+
+	u.failures++
+	if u.failures >= b.MaxFailures {
+		u.ejected = time.Now().Add(b.EjectFor)
+	}
+}
+
+// Probe checks health of upstreams with GET request of path every interval,
+// until ctx is done. Upstream which does not answer with 2xx status is ejected,
+// and healthy one is restored. Probes run in background, error is returned
+// only for invalid path.
+func (b *Balancer) Probe(ctx context.Context, path string, interval time.Duration) error {
+	p, err := url.Parse(path)
+	if err != nil {
+		return err
+	}
+
+	check := func(u *upstream) {
+		pctx, cancel := context.WithTimeout(ctx, interval)
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(pctx, http.MethodGet, u.resolve(p).String(), nil)
+		if err != nil {
+			return
+		}
+
+		res, err := b.Endpoint.Do(req)
+		ok := err == nil && res.StatusCode >= 200 && res.StatusCode < 300
+		drain(res)
+		if ctx.Err() != nil {
+			return
+		}
+
+		u.mu.Lock()
+		if ok {
+			u.failures, u.ejected = 0, time.Time{}
+		} else {
+			u.failures, u.ejected = b.MaxFailures, time.Now().Add(b.EjectFor)
+		}
+		u.mu.Unlock()
+	}
+
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+
+		for {
+			var wg sync.WaitGroup
+			for _, u := range b.upstreams {
+				wg.Add(1)
+				go func(u *upstream) {
+					defer wg.Done()
+					check(u)
+				}(u)
+			}
+			wg.Wait()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (u *upstream) healthy(now time.Time) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return !now.Before(u.ejected)
+}
+
+// resolve puts path and query of target on top of upstream base url.
+func (u *upstream) resolve(target *url.URL) *url.URL {
+	c := *u.base
+	c.Path = u.base.Path + target.Path
+	if target.RawPath != "" {
+		c.RawPath = u.base.EscapedPath() + target.RawPath
+	} else {
+		c.RawPath = ""
+	}
+	c.RawQuery = target.RawQuery
+	c.Fragment = ""
+	return &c
+}
@@ -0,0 +1,88 @@
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/livechat/gokit/test/is"
+	"github.com/livechat/gokit/web/client"
+)
+
+func upstream(status *int32, calls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		w.WriteHeader(int(atomic.LoadInt32(status)))
+	}))
+}
+
+func TestBalanced(t *testing.T) {
+	var (
+		okStatus, badStatus int32 = 200, 503
+		okCalls, badCalls   int32
+	)
+	good, bad := upstream(&okStatus, &okCalls), upstream(&badStatus, &badCalls)
+	defer good.Close()
+	defer bad.Close()
+
+	b := client.Balanced([]string{bad.URL, good.URL}, client.RoundRobin)
+	b.MaxFailures = 2
+	c := client.NewCaller(b, client.ResponseError(nil))
+
+	for i := 0; i < 6; i++ {
+		is.Ok(t, c.Call(client.Option{URL: "/v2/tags"}))
+	}
+
+	// failed GET requests are sent to the next upstream, bad one is ejected after 2 failures
+	is.Equal(t, int32(2), atomic.LoadInt32(&badCalls))
+	is.Equal(t, int32(6), atomic.LoadInt32(&okCalls))
+
+	// non idempotent requests are not repeated
+	b = client.Balanced([]string{bad.URL, good.URL}, client.RoundRobin)
+	c = client.NewCaller(b, client.ResponseError(nil))
+	is.True(t, client.IsServerError(c.Call(client.Option{URL: "/v2/tags", Method: "POST"})), "server error expected")
+
+	// streamed body can not be sent again, failure of upstream is returned
+	b = client.Balanced([]string{bad.URL, good.URL}, client.RoundRobin)
+	c = client.NewCaller(b, client.ResponseError(nil), client.MultipartRequest())
+	upload := client.Multipart{Files: []client.File{{Field: "file", Name: "tags.csv", Reader: strings.NewReader("id\n1\n")}}}
+	err := c.Call(client.Option{URL: "/v2/tags/import", Method: "PUT", Request: upload})
+	is.True(t, client.IsServerError(err), "server error expected, got %v", err)
+
+	// url of the caller's request is kept
+	b = client.Balanced([]string{good.URL}, client.RoundRobin)
+	req, _ := http.NewRequest(http.MethodGet, "/v2/tags", nil)
+	res, err := b.Do(req)
+	is.Ok(t, err)
+	res.Body.Close()
+	is.Equal(t, "/v2/tags", req.URL.String())
+}
+
+func TestBalancedProbe(t *testing.T) {
+	var (
+		status int32 = 503
+		calls  int32
+	)
+	s := upstream(&status, &calls)
+	defer s.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := client.Balanced([]string{s.URL, "http://127.0.0.1:1"}, client.LeastInFlight)
+	is.Ok(t, b.Probe(ctx, "/health", 10*time.Millisecond))
+
+	atomic.StoreInt32(&status, 200)
+	time.Sleep(50 * time.Millisecond)
+	before := atomic.LoadInt32(&calls)
+
+	c := client.NewCaller(b, client.ResponseError(nil))
+	for i := 0; i < 3; i++ {
+		is.Ok(t, c.Call(client.Option{URL: "/v2/tags", Method: "POST"}))
+	}
+	is.True(t, atomic.LoadInt32(&calls)-before >= 3, "requests should go to healthy upstream")
+}
@@ -0,0 +1,128 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrSkipped is a result of batch call which was not sent, because other call failed.
+var ErrSkipped = errors.New("batch: call skipped after failure")
+
+// BatchConfig configures Batch, zero values are replaced with defaults.
+type BatchConfig struct {
+	// Concurrency is maximum number of calls sent at once (default 4).
+	Concurrency int
+	// FailFast stops batch on first failure, calls in progress are cancelled
+	// and calls which were not sent yet are skipped.
+	FailFast bool
+}
+
+// BatchResult is outcome of single batch call.
+type BatchResult struct {
+	Option Option
+	Err    error
+}
+
+// BatchError aggregates errors of all failed batch calls.
+type BatchError struct {
+	Errors []error
+	Total  int
+}
+
+func (e *BatchError) Error() string {
+	m := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		m[i] = err.Error()
+	}
+
+	return fmt.Sprintf("%d of %d calls failed: %s", len(e.Errors), e.Total, strings.Join(m, "; "))
+}
+
+// Unwrap gives access to every cause with errors.Is and errors.As.
+func (e *BatchError) Unwrap() []error { return e.Errors }
+
+// Batch sends all options through Caller with limited concurrency. Results are
+// returned in order of options, error is a *BatchError when any of calls failed.
+func Batch(ctx context.Context, c Caller, cfg BatchConfig, oo ...Option) ([]BatchResult, error) {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 4
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		results = make([]BatchResult, len(oo))
+		slots   = make(chan struct{}, cfg.Concurrency)
+		wg      sync.WaitGroup
+		once    sync.Once
+		failed  = make(chan struct{})
+	)
+
+	for i := range oo {
+		results[i].Option = oo[i]
+
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+
+		if ctx.Err() != nil {
+			results[i].Err = ErrSkipped
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			err := c.CallContext(ctx, oo[i])
+			if err != nil && cfg.FailFast {
+				once.Do(func() {
+					close(failed)
+					cancel()
+				})
+			}
+			results[i].Err = err
+		}(i)
+	}
+
+	wg.Wait()
+
+	be := &BatchError{Total: len(oo)}
+	for _, r := range results {
+		if r.Err == nil || r.Err == ErrSkipped {
+			continue
+		}
+
+		// calls cancelled by fail fast are consequence of other failure
+		select {
+		case <-failed:
+			if errors.Is(r.Err, context.Canceled) {
+				continue
+			}
+		default:
+		}
+
+		be.Errors = append(be.Errors, r.Err)
+	}
+
+	if len(be.Errors) > 0 {
+		return results, be
+	}
+
+	// parent context was done before all calls were sent
+	if err := ctx.Err(); err != nil {
+		for _, r := range results {
+			if r.Err != nil {
+				return results, err
+			}
+		}
+	}
+
+	return results, nil
+}
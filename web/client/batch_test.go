@@ -0,0 +1,71 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/livechat/gokit/test/is"
+	"github.com/livechat/gokit/web/client"
+)
+
+func TestBatch(t *testing.T) {
+	var active, peak int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&active, 1)
+		defer atomic.AddInt32(&active, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer s.Close()
+
+	c := client.NewCaller(s.Client(), client.ResponseError(nil))
+	oo := []client.Option{
+		{URL: s.URL + "/a"}, {URL: s.URL + "/missing"}, {URL: s.URL + "/b"},
+		{URL: s.URL + "/c"}, {URL: s.URL + "/missing"}, {URL: s.URL + "/d"},
+	}
+
+	res, err := client.Batch(context.Background(), c, client.BatchConfig{Concurrency: 2}, oo...)
+	is.True(t, atomic.LoadInt32(&peak) <= 2, "concurrency exceeded: %d", peak)
+	is.Equal(t, len(oo), len(res))
+	is.Ok(t, res[0].Err)
+	is.True(t, client.IsNotFound(res[1].Err), "not found expected, got %v", res[1].Err)
+
+	var be *client.BatchError
+	is.True(t, errors.As(err, &be), "batch error expected, got %v", err)
+	is.Equal(t, 2, len(be.Errors))
+	is.True(t, client.IsNotFound(err), "cause should be kept")
+}
+
+func TestBatchFailFast(t *testing.T) {
+	var calls int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer s.Close()
+
+	c := client.NewCaller(s.Client(), client.ResponseError(nil))
+	oo := make([]client.Option, 10)
+	for i := range oo {
+		oo[i] = client.Option{URL: s.URL}
+	}
+
+	res, err := client.Batch(context.Background(), c, client.BatchConfig{Concurrency: 1, FailFast: true}, oo...)
+	is.True(t, client.IsServerError(err), "server error expected, got %v", err)
+	is.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	is.True(t, errors.Is(res[9].Err, client.ErrSkipped), "skipped expected, got %v", res[9].Err)
+}
@@ -0,0 +1,147 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrBodyTooLarge is matched by BodyTooLargeError with errors.Is.
+	ErrBodyTooLarge = errors.New("response body too large")
+	// ErrBodyReadTimeout is returned when single read of response body takes
+	// longer than BodyConfig.ReadTimeout.
+	ErrBodyReadTimeout = errors.New("response body read timeout")
+)
+
+// BodyTooLargeError is returned when response body exceeds BodyConfig.MaxSize.
+type BodyTooLargeError struct {
+	Limit int64
+}
+
+func (e *BodyTooLargeError) Error() string {
+	return fmt.Sprintf("%s: limit of %d bytes exceeded", ErrBodyTooLarge, e.Limit)
+}
+
+func (e *BodyTooLargeError) Is(target error) bool { return target == ErrBodyTooLarge }
+
+// BodyConfig limits response bodies read by middlewares, zero values are replaced with defaults.
+type BodyConfig struct {
+	// MaxSize is maximum number of body bytes (default 10MB when set by BodyLimit).
+	MaxSize int64
+	// ReadTimeout is maximum duration of single read from connection (default 30s).
+	ReadTimeout time.Duration
+}
+
+// DefaultBodyConfig is used by middlewares when BodyLimit is not set, size of
+// the body is not limited then, so large responses as CSV exports are read whole.
+// Body is kept in memory until the call ends, so memory use grows with size of
+// the response, JSONStreamResponse or Download should be used for large ones.
+var DefaultBodyConfig = BodyConfig{ReadTimeout: 30 * time.Second}
+
+// BodyLimit sets limits of response body read by Response, Logging, Trace,
+// ResponseError, Cache and Recorder. It has to be listed after them.
+func BodyLimit(c BodyConfig) Middleware {
+	if c.MaxSize <= 0 {
+		c.MaxSize = 10 << 20
+	}
+	if c.ReadTimeout <= 0 {
+		c.ReadTimeout = DefaultBodyConfig.ReadTimeout
+	}
+
+	return func(e Endpoint) Endpoint {
+		return EndpointFunc(func(r *http.Request) (*http.Response, error) {
+			return e.Do(r.WithContext(context.WithValue(r.Context(), bodyKey, c)))
+		})
+	}
+}
+
+// capturedBody is response body read into memory, reading it ends with the
+// error which interrupted capture.
+type capturedBody struct {
+	*bytes.Reader
+	data []byte
+	err  error
+}
+
+func (b *capturedBody) Read(p []byte) (int, error) {
+	n, err := b.Reader.Read(p)
+	if err == io.EOF && b.err != nil {
+		return n, b.err
+	}
+	return n, err
+}
+
+func (b *capturedBody) Close() error { return nil }
+
+// capture reads response body once within limits given by BodyLimit and
+// replaces it with in-memory copy, so every middleware inspecting the body
+// shares the same bytes. Body read so far is kept when capture fails.
+func capture(r *http.Request, res *http.Response) ([]byte, error) {
+	if res.Body == nil || res.Body == http.NoBody {
+		return nil, nil
+	}
+
+	if b, ok := res.Body.(*capturedBody); ok {
+		return b.data, b.err
+	}
+
+	c, ok := r.Context().Value(bodyKey).(BodyConfig)
+	if !ok {
+		c = DefaultBodyConfig
+	}
+
+	var (
+		body     = res.Body
+		timedOut int32
+		buf      = &bytes.Buffer{}
+		p        = make([]byte, 32<<10)
+		err      error
+	)
+
+	// blocked read is interrupted by closing the body
+	t := time.AfterFunc(c.ReadTimeout, func() {
+		atomic.StoreInt32(&timedOut, 1)
+		body.Close()
+	})
+
+	for {
+		t.Reset(c.ReadTimeout)
+		n, rerr := body.Read(p)
+		buf.Write(p[:n])
+
+		if c.MaxSize > 0 && int64(buf.Len()) > c.MaxSize {
+			buf.Truncate(int(c.MaxSize))
+			err = &BodyTooLargeError{Limit: c.MaxSize}
+			break
+		}
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			err = rerr
+			break
+		}
+	}
+
+	t.Stop()
+	body.Close()
+
+	switch {
+	case atomic.LoadInt32(&timedOut) == 1 && err != nil:
+		err = ErrBodyReadTimeout
+	case err != nil && r.Context().Err() != nil:
+		// body read is interrupted by cancelled or expired request context
+		err = r.Context().Err()
+	}
+
+	data := buf.Bytes()
+	res.Body = &capturedBody{Reader: bytes.NewReader(data), data: data, err: err}
+
+	return data, err
+}
@@ -0,0 +1,78 @@
+package client_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/livechat/gokit/test/is"
+	"github.com/livechat/gokit/web/client"
+)
+
+func TestBodyLimit(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"text":"` + strings.Repeat("x", 2048) + `"}`))
+	}))
+	defer s.Close()
+
+	var logged []string
+	log := func(label string, _ ...interface{}) { logged = append(logged, label) }
+
+	c := client.NewCaller(s.Client(),
+		client.JSONResponse(),
+		client.Logging(log),
+		client.BodyLimit(client.BodyConfig{MaxSize: 1024}),
+	)
+
+	var out map[string]string
+	err := c.Call(client.Option{URL: s.URL, Response: &out})
+	is.True(t, errors.Is(err, client.ErrBodyTooLarge), "too large expected, got %v", err)
+
+	var terr *client.BodyTooLargeError
+	is.True(t, errors.As(err, &terr), "typed error expected")
+	is.Equal(t, int64(1024), terr.Limit)
+	is.True(t, strings.Contains(strings.Join(logged, ","), "HTTP.response.error"), "error should be logged")
+}
+
+func TestBodyNotLimitedByDefault(t *testing.T) {
+	row := strings.Repeat("x", 1023) + "\n"
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Write([]byte("text\n"))
+		for i := 0; i < 12<<10; i++ {
+			w.Write([]byte(row))
+		}
+	}))
+	defer s.Close()
+
+	c := client.NewCaller(s.Client(), client.CSVResponse())
+
+	var out []map[string]string
+	is.Ok(t, c.Call(client.Option{URL: s.URL, Response: &out}))
+	is.Equal(t, 12<<10, len(out))
+}
+
+func TestBodyReadTimeout(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"text":`))
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer s.Close()
+
+	c := client.NewCaller(s.Client(),
+		client.JSONResponse(),
+		client.BodyLimit(client.BodyConfig{ReadTimeout: 20 * time.Millisecond}),
+	)
+
+	var out map[string]string
+	err := c.Call(client.Option{URL: s.URL, Response: &out})
+	is.True(t, errors.Is(err, client.ErrBodyReadTimeout), "timeout expected, got %v", err)
+}
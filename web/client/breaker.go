@@ -0,0 +1,238 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is matched by errors.Is for every error returned by CircuitBreaker
+// while requests to the host are rejected.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitOpenError is returned instead of calling Endpoint when host circuit is open.
+type CircuitOpenError struct {
+	Host  string
+	Until time.Time
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s: %s until %s", ErrCircuitOpen, e.Host, e.Until.Format(time.RFC3339))
+}
+
+func (e *CircuitOpenError) Is(target error) bool { return target == ErrCircuitOpen }
+
+// BreakerState is a state of single host circuit.
+type BreakerState int
+
+const (
+	// StateClosed lets all requests pass and counts failures.
+	StateClosed BreakerState = iota
+	// StateOpen rejects all requests until cool-down passes.
+	StateOpen
+	// StateHalfOpen lets limited number of probe requests pass.
+	StateHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// BreakerConfig configures CircuitBreaker, zero values are replaced with defaults.
+type BreakerConfig struct {
+	// Threshold is number of consecutive failures which opens circuit (default 5).
+	Threshold int
+	// CoolDown is time for which open circuit rejects requests (default 30s).
+	CoolDown time.Duration
+	// Probes is number of requests let through in half-open state, all of
+	// them have to succeed to close circuit again (default 1).
+	Probes int
+	// IsFailure decides if exchange counts as failure, by default transport errors
+	// and 5xx responses are failures.
+	IsFailure func(*http.Response, error) bool
+	// OnStateChange is called after host circuit changes its state.
+	OnStateChange func(host string, from, to BreakerState)
+}
+
+// CircuitBreaker tracks failures of every host separately and stops calling
+// host which keeps failing. While circuit is open, *CircuitOpenError is returned
+// immediately, after cool-down a few probe requests decide if host is back.
+func CircuitBreaker(c BreakerConfig) Middleware {
+	if c.Threshold <= 0 {
+		c.Threshold = 5
+	}
+	if c.CoolDown <= 0 {
+		c.CoolDown = 30 * time.Second
+	}
+	if c.Probes <= 0 {
+		c.Probes = 1
+	}
+	if c.IsFailure == nil {
+		c.IsFailure = isFailure
+	}
+
+	var (
+		mu       sync.Mutex
+		circuits = map[string]*circuit{}
+	)
+
+	get := func(host string) *circuit {
+		mu.Lock()
+		defer mu.Unlock()
+
+		cr, ok := circuits[host]
+		if !ok {
+			cr = &circuit{host: host, config: &c}
+			circuits[host] = cr
+		}
+		return cr
+	}
+
+	return func(e Endpoint) Endpoint {
+		return EndpointFunc(func(r *http.Request) (*http.Response, error) {
+			cr := get(r.URL.Host)
+
+			probe, err := cr.allow(time.Now())
+			if err != nil {
+				return nil, err
+			}
+
+			res, err := e.Do(r)
+
+			switch {
+			case r.Context().Err() != nil:
+				// cancelled by caller, it says nothing about host
+				cr.release(probe)
+			case c.IsFailure(res, err):
+				cr.failure(probe, time.Now())
+			default:
+				cr.success(probe)
+			}
+
+			return res, err
+		})
+	}
+}
+
+func isFailure(res *http.Response, err error) bool {
+	if res == nil {
+		return err != nil && !errors.Is(err, context.Canceled)
+	}
+
+	return res.StatusCode >= 500
+}
+
+type circuit struct {
+	mu        sync.Mutex
+	host      string
+	config    *BreakerConfig
+	state     BreakerState
+	failures  int
+	successes int
+	probes    int
+	until     time.Time
+	changes   []BreakerState
+}
+
+// allow checks if request might be sent, it returns true when request is a probe.
+func (c *circuit) allow(now time.Time) (bool, error) {
+	c.mu.Lock()
+	defer c.notify()
+
+	if c.state == StateOpen {
+		if now.Before(c.until) {
+			return false, &CircuitOpenError{Host: c.host, Until: c.until}
+		}
+		c.set(StateHalfOpen)
+	}
+
+	if c.state == StateHalfOpen {
+		if c.probes >= c.config.Probes {
+			return false, &CircuitOpenError{Host: c.host, Until: c.until}
+		}
+		c.probes++
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (c *circuit) success(probe bool) {
+	c.mu.Lock()
+	defer c.notify()
+
+	c.failures = 0
+	if probe && c.state == StateHalfOpen {
+		c.probes--
+		if c.successes++; c.successes >= c.config.Probes {
+			c.set(StateClosed)
+		}
+	}
+}
+
+func (c *circuit) failure(probe bool, now time.Time) {
+	c.mu.Lock()
+	defer c.notify()
+
+	if probe && c.state == StateHalfOpen && c.probes > 0 {
+		c.probes--
+	}
+
+	c.failures++
+	if c.state == StateHalfOpen || (c.state == StateClosed && c.failures >= c.config.Threshold) {
+		c.until = now.Add(c.config.CoolDown)
+		c.set(StateOpen)
+	}
+}
+
+func (c *circuit) release(probe bool) {
+	if !probe {
+		return
+	}
+
+	c.mu.Lock()
+	if c.state == StateHalfOpen && c.probes > 0 {
+		c.probes--
+	}
+	c.mu.Unlock()
+}
+
+// set changes state, it has to be called with locked mutex.
+func (c *circuit) set(to BreakerState) {
+	from := c.state
+	if from == to {
+		return
+	}
+
+	c.state = to
+	c.failures = 0
+	c.successes = 0
+	c.probes = 0
+	c.changes = append(c.changes, from, to)
+}
+
+// notify unlocks circuit and passes collected state changes to the hook.
+func (c *circuit) notify() {
+	changes := c.changes
+	c.changes = nil
+	c.mu.Unlock()
+
+	if c.config.OnStateChange == nil {
+		return
+	}
+
+	for i := 0; i+1 < len(changes); i += 2 {
+		c.config.OnStateChange(c.host, changes[i], changes[i+1])
+	}
+}
@@ -0,0 +1,64 @@
+package client_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/livechat/gokit/test/is"
+	"github.com/livechat/gokit/web/client"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var (
+		calls  int32
+		status int32 = http.StatusInternalServerError
+	)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+	}))
+	defer s.Close()
+
+	var (
+		mu      sync.Mutex
+		changes []string
+	)
+	c := client.NewCaller(s.Client(),
+		client.ResponseError(nil),
+		client.CircuitBreaker(client.BreakerConfig{
+			Threshold: 2,
+			CoolDown:  20 * time.Millisecond,
+			OnStateChange: func(host string, from, to client.BreakerState) {
+				mu.Lock()
+				changes = append(changes, from.String()+">"+to.String())
+				mu.Unlock()
+			},
+		}),
+	)
+
+	o := client.Option{URL: s.URL}
+	is.Err(t, c.Call(o), "first failure")
+	is.Err(t, c.Call(o), "second failure")
+
+	err := c.Call(o)
+	is.True(t, errors.Is(err, client.ErrCircuitOpen), "open circuit expected, got %v", err)
+	var oerr *client.CircuitOpenError
+	is.True(t, errors.As(err, &oerr), "typed error expected")
+	is.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	time.Sleep(30 * time.Millisecond)
+	atomic.StoreInt32(&status, http.StatusOK)
+
+	is.Ok(t, c.Call(o))
+	is.Ok(t, c.Call(o))
+	is.Equal(t, int32(4), atomic.LoadInt32(&calls))
+
+	mu.Lock()
+	defer mu.Unlock()
+	is.Equal(t, []string{"closed>open", "open>half-open", "half-open>closed"}, changes)
+}
@@ -0,0 +1,318 @@
+package client
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheStore keeps responses stored by Cache middleware.
+type CacheStore interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, c *CachedResponse)
+	Delete(key string)
+}
+
+// CachedResponse is a response snapshot kept in CacheStore.
+type CachedResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	// Vary keeps values of request headers listed by response Vary header.
+	Vary map[string]string
+	// Expires is time after which response has to be revalidated.
+	Expires time.Time
+}
+
+// Cache serves GET and HEAD responses from store as long as they are fresh according
+// to Cache-Control max-age or Expires response headers. Stale responses with ETag or
+// Last-Modified are revalidated with If-None-Match or If-Modified-Since request and
+// served from store again when server answers 304 Not Modified.
+//
+// Store is treated as shared, as it might be used by Callers of different tokens.
+// Responses are kept per Authorization header, and private responses, or responses
+// to authorized requests which are not marked as public, are never stored.
+func Cache(s CacheStore) Middleware {
+	return func(e Endpoint) Endpoint {
+		return EndpointFunc(func(r *http.Request) (*http.Response, error) {
+			key := cacheKey(r.Method, r)
+
+			if r.Method != "" && r.Method != http.MethodGet && r.Method != http.MethodHead {
+				res, err := e.Do(r)
+				if err == nil && res.StatusCode < 400 {
+					// unsafe method invalidates representation of resource
+					s.Delete(cacheKey(http.MethodGet, r))
+					s.Delete(cacheKey(http.MethodHead, r))
+				}
+				return res, err
+			}
+
+			rcc := cacheControl(r.Header)
+			if _, ok := rcc["no-store"]; ok {
+				return e.Do(r)
+			}
+			_, revalidate := rcc["no-cache"]
+
+			c, ok := s.Get(key)
+			if ok && !c.matches(r) {
+				ok = false
+			}
+
+			if ok && !revalidate && time.Now().Before(c.Expires) {
+				return c.response(r), nil
+			}
+
+			req := r
+			conditional := ok && r.Header.Get("If-None-Match") == "" && r.Header.Get("If-Modified-Since") == ""
+			if conditional {
+				etag, modified := c.Header.Get("ETag"), c.Header.Get("Last-Modified")
+				if etag == "" && modified == "" {
+					conditional = false
+				} else {
+					req = r.Clone(r.Context())
+					if etag != "" {
+						req.Header.Set("If-None-Match", etag)
+					}
+					if modified != "" {
+						req.Header.Set("If-Modified-Since", modified)
+					}
+				}
+			}
+
+			res, err := e.Do(req)
+			if err != nil {
+				return res, err
+			}
+
+			if conditional && res.StatusCode == http.StatusNotModified {
+				drain(res)
+
+				// stored response might be read concurrently, so refresh its copy
+				u := *c
+				u.Header = c.Header.Clone()
+				for k, v := range res.Header {
+					u.Header[k] = v
+				}
+				if expires, ok := freshness(u.Header, time.Now()); ok {
+					u.Expires = expires
+				}
+				s.Set(key, &u)
+
+				return u.response(r), nil
+			}
+
+			if res.StatusCode != http.StatusOK {
+				return res, err
+			}
+
+			expires, cacheable := freshness(res.Header, time.Now())
+			if !cacheable || !shareable(r, res.Header) {
+				return res, err
+			}
+
+			body, rerr := capture(r, res)
+			if rerr != nil {
+				return res, rerr
+			}
+
+			vary, ok := varyValues(r, res.Header)
+			if !ok {
+				return res, err
+			}
+
+			s.Set(key, &CachedResponse{
+				StatusCode: res.StatusCode,
+				Header:     res.Header.Clone(),
+				Body:       body,
+				Vary:       vary,
+				Expires:    expires,
+			})
+
+			return res, err
+		})
+	}
+}
+
+// cacheKey identifies response of method and url, requests with different
+// credentials never share the key.
+func cacheKey(method string, r *http.Request) string {
+	key := method + " " + r.URL.String()
+	if a := r.Header.Get("Authorization"); a != "" {
+		h := sha256.Sum256([]byte(a))
+		key += " " + hex.EncodeToString(h[:16])
+	}
+	return key
+}
+
+// shareable tells if response might be kept in shared store.
+func shareable(r *http.Request, h http.Header) bool {
+	cc := cacheControl(h)
+	if _, ok := cc["private"]; ok {
+		return false
+	}
+
+	if r.Header.Get("Authorization") != "" {
+		_, public := cc["public"]
+		_, shared := cc["s-maxage"]
+		return public || shared
+	}
+
+	return true
+}
+
+func (c *CachedResponse) matches(r *http.Request) bool {
+	for k, v := range c.Vary {
+		if r.Header.Get(k) != v {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *CachedResponse) response(r *http.Request) *http.Response {
+	return newResponse(r, c.StatusCode, c.Header, c.Body)
+}
+
+// freshness tells until when response is fresh, and if it might be stored at all.
+func freshness(h http.Header, now time.Time) (time.Time, bool) {
+	cc := cacheControl(h)
+	if _, ok := cc["no-store"]; ok {
+		return time.Time{}, false
+	}
+
+	validated := h.Get("ETag") != "" || h.Get("Last-Modified") != ""
+	if _, ok := cc["no-cache"]; ok {
+		return now, validated
+	}
+
+	v, ok := cc["s-maxage"]
+	if !ok {
+		v, ok = cc["max-age"]
+	}
+	if ok {
+		if s, err := strconv.Atoi(v); err == nil {
+			if age, err := strconv.Atoi(h.Get("Age")); err == nil {
+				s -= age
+			}
+			return now.Add(time.Duration(s) * time.Second), true
+		}
+	}
+
+	if v := h.Get("Expires"); v != "" {
+		t, err := http.ParseTime(v)
+		if err != nil {
+			// invalid Expires value means already expired
+			return now, validated
+		}
+		return t, true
+	}
+
+	return now, validated
+}
+
+func varyValues(r *http.Request, h http.Header) (map[string]string, bool) {
+	vary := map[string]string{}
+	for _, v := range h.Values("Vary") {
+		for _, k := range strings.Split(v, ",") {
+			k = strings.TrimSpace(k)
+			if k == "*" {
+				return nil, false
+			}
+			if k != "" {
+				vary[http.CanonicalHeaderKey(k)] = r.Header.Get(k)
+			}
+		}
+	}
+	return vary, true
+}
+
+func cacheControl(h http.Header) map[string]string {
+	cc := map[string]string{}
+	for _, v := range h.Values("Cache-Control") {
+		for _, d := range strings.Split(v, ",") {
+			d = strings.TrimSpace(d)
+			if d == "" {
+				continue
+			}
+			k, v := d, ""
+			if i := strings.Index(d, "="); i != -1 {
+				k, v = d[:i], strings.Trim(d[i+1:], `"`)
+			}
+			cc[strings.ToLower(k)] = v
+		}
+	}
+	return cc
+}
+
+// LRU is in-memory CacheStore, which keeps at most given number of responses
+// and evicts least recently used one when limit is exceeded.
+type LRU struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	items map[string]*list.Element
+}
+
+type lruItem struct {
+	key   string
+	value *CachedResponse
+}
+
+// NewLRU creates in-memory CacheStore limited to size responses.
+func NewLRU(size int) *LRU {
+	if size <= 0 {
+		size = 1
+	}
+
+	return &LRU{
+		size:  size,
+		order: list.New(),
+		items: map[string]*list.Element{},
+	}
+}
+
+func (l *LRU) Get(key string) (*CachedResponse, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	i, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+	l.order.MoveToFront(i)
+
+	return i.Value.(*lruItem).value, true
+}
+
+func (l *LRU) Set(key string, c *CachedResponse) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if i, ok := l.items[key]; ok {
+		i.Value.(*lruItem).value = c
+		l.order.MoveToFront(i)
+		return
+	}
+
+	l.items[key] = l.order.PushFront(&lruItem{key: key, value: c})
+	for l.order.Len() > l.size {
+		last := l.order.Back()
+		l.order.Remove(last)
+		delete(l.items, last.Value.(*lruItem).key)
+	}
+}
+
+func (l *LRU) Delete(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if i, ok := l.items[key]; ok {
+		l.order.Remove(i)
+		delete(l.items, key)
+	}
+}
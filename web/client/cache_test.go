@@ -0,0 +1,96 @@
+package client_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/livechat/gokit/test/is"
+	"github.com/livechat/gokit/web/client"
+)
+
+func TestCacheMaxAge(t *testing.T) {
+	var calls int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"licenceID":1}`))
+	}))
+	defer s.Close()
+
+	c := client.NewCaller(s.Client(), client.Cache(client.NewLRU(10)), client.JSONResponse())
+
+	for i := 0; i < 3; i++ {
+		var out struct{ LicenceID int }
+		is.Ok(t, c.Call(client.Option{URL: s.URL, Response: &out}))
+		is.Equal(t, 1, out.LicenceID)
+	}
+	is.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestCacheRevalidation(t *testing.T) {
+	var calls, modified int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&modified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte(`{"licenceID":2}`))
+	}))
+	defer s.Close()
+
+	c := client.NewCaller(s.Client(), client.Cache(client.NewLRU(10)), client.JSONResponse())
+
+	for i := 0; i < 3; i++ {
+		var out struct{ LicenceID int }
+		is.Ok(t, c.Call(client.Option{URL: s.URL, Response: &out}))
+		is.Equal(t, 2, out.LicenceID)
+	}
+	is.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	is.Equal(t, int32(2), atomic.LoadInt32(&modified))
+}
+
+func TestCacheSharedBetweenTokens(t *testing.T) {
+	var calls int32
+	cc := "private, max-age=60"
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Cache-Control", cc)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"token":"` + r.Header.Get("Authorization") + `"}`))
+	}))
+	defer s.Close()
+
+	store := client.NewLRU(10)
+	caller := func(token string) client.Caller {
+		return client.NewCaller(s.Client(), client.Cache(store), client.JSONResponse(), client.Authorization(token))
+	}
+	alice, bob := caller("alice"), caller("bob")
+
+	call := func(c client.Caller) string {
+		var out map[string]string
+		is.Ok(t, c.Call(client.Option{URL: s.URL, Response: &out}))
+		return out["token"]
+	}
+
+	// private responses are never stored
+	is.Equal(t, "alice", call(alice))
+	is.Equal(t, "bob", call(bob))
+	is.Equal(t, "alice", call(alice))
+	is.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	// public responses are stored per token
+	cc = "public, max-age=60"
+	atomic.StoreInt32(&calls, 0)
+	is.Equal(t, "alice", call(alice))
+	is.Equal(t, "bob", call(bob))
+	is.Equal(t, "alice", call(alice))
+	is.Equal(t, "bob", call(bob))
+	is.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
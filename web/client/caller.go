@@ -2,7 +2,11 @@ package client
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"net/url"
+	"regexp"
+	"time"
 )
 
 // Caller is just simplified endpoint invoker. Use it when you do not need to handle own http request and response.
@@ -10,6 +14,10 @@ type Caller interface {
 	// Call will create decorated http request and response. Parameter (in) will be decoded into http request
 	// and parameter (out) will be encoded from http response.
 	Call(Option) error
+
+	// CallContext works like Call, but the http request is bound to given context, so
+	// its cancellation and deadline are visible for every Middleware and the Endpoint.
+	CallContext(context.Context, Option) error
 }
 
 type caller struct {
@@ -17,39 +25,154 @@ type caller struct {
 }
 
 type Option struct {
+	// URL might be a template with {name} placeholders filled by Params.
 	URL      string
 	Method   string
 	Request  interface{}
 	Response interface{}
+
+	// Params are path escaped values of URL placeholders.
+	Params map[string]string
+	// Query is added to URL query, it's url.Values, map[string]string or
+	// struct with fields tagged by `query:"name,omitempty"`.
+	Query interface{}
+	// Header is set on request of this call.
+	Header http.Header
+	// Timeout limits duration of this call, including reading of response.
+	Timeout time.Duration
+	// Middlewares wrap endpoint for this call only, they run before
+	// middlewares given to NewCaller.
+	Middlewares []Middleware
+}
+
+// contextKey is unexported, so values kept by Caller in request context can not
+// collide with keys defined in other packages.
+type contextKey int
+
+const (
+	inKey contextKey = iota
+	outKey
+	hookKey
+	bodyKey
+	routeKey
+)
+
+// requestValue returns (in) parameter given by Option.Request.
+func requestValue(r *http.Request) interface{} { return r.Context().Value(inKey) }
+
+// responseValue returns (out) parameter given by Option.Response.
+func responseValue(r *http.Request) interface{} { return r.Context().Value(outKey) }
+
+// withResponseHook lets helpers built on top of Caller see http request and response
+// of the call, hook is called before response body is closed. Request is the one sent
+// by Caller, as response given by Endpoint might not have it set.
+func withResponseHook(ctx context.Context, fn func(*http.Request, *http.Response)) context.Context {
+	return context.WithValue(ctx, hookKey, fn)
 }
 
 func (a *caller) Call(r Option) error {
-	ctx := context.WithValue(
-		context.WithValue(
-			context.Background(),
-			"in",
-			r.Request),
-		"out",
-		r.Response,
-	)
-
-	// prepare http request with background context. Context will help decorators
-	// in wrapping extra behavior for request and response.
-	req, err := http.NewRequest(r.Method, r.URL, nil)
-	req = req.WithContext(ctx)
+	return a.CallContext(context.Background(), r)
+}
+
+func (a *caller) CallContext(ctx context.Context, r Option) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+
+	u, route, err := r.url()
+	if err != nil {
+		return err
+	}
+	if route != "" {
+		ctx = context.WithValue(ctx, routeKey, route)
+	}
+
+	// Context will help decorators in wrapping extra behavior for request and response.
+	ctx = context.WithValue(context.WithValue(ctx, inKey, r.Request), outKey, r.Response)
+
+	req, err := http.NewRequestWithContext(ctx, r.Method, u, nil)
 	if err != nil {
 		return err
 	}
+	for k, v := range r.Header {
+		req.Header[http.CanonicalHeaderKey(k)] = append([]string(nil), v...)
+	}
+
+	e := a.endpoint
+	for _, m := range r.Middlewares {
+		e = m(e)
+	}
 
 	// call http resource and close body to let another calls using same endpoint tcp connection
-	res, err := a.endpoint.Do(req)
+	res, err := e.Do(req)
+	if fn, ok := ctx.Value(hookKey).(func(*http.Request, *http.Response)); ok && res != nil {
+		fn(req, res)
+	}
 	if err != nil {
+		if res != nil && res.Body != nil {
+			res.Body.Close()
+		}
 		return err
 	}
 
 	return res.Body.Close()
 }
 
+var placeholder = regexp.MustCompile(`\{([^{}/]+)\}`)
+
+// url expands template of URL with Params and adds Query. Route is path of
+// the template with :name placeholders, or empty when URL is not a template.
+func (o Option) url() (string, string, error) {
+	var missing string
+	u := placeholder.ReplaceAllStringFunc(o.URL, func(m string) string {
+		name := m[1 : len(m)-1]
+		v, ok := o.Params[name]
+		if !ok && missing == "" {
+			missing = name
+		}
+		return url.PathEscape(v)
+	})
+	if missing != "" {
+		return "", "", fmt.Errorf("client: missing value of %q path parameter of %s", missing, o.URL)
+	}
+
+	var route string
+	if u != o.URL {
+		t, err := url.Parse(placeholder.ReplaceAllString(o.URL, ":$1"))
+		if err != nil {
+			return "", "", err
+		}
+		route = t.Path
+	}
+
+	if o.Query == nil {
+		return u, route, nil
+	}
+
+	q, err := EncodeQuery(o.Query)
+	if err != nil {
+		return "", "", err
+	}
+
+	p, err := url.Parse(u)
+	if err != nil {
+		return "", "", err
+	}
+	v := p.Query()
+	for k := range q {
+		v[k] = q[k]
+	}
+	p.RawQuery = v.Encode()
+
+	return p.String(), route, nil
+}
+
 // Caller decorates given endpoint with extra behavior and simplifies http client.
 func NewCaller(e Endpoint, ms ...Middleware) Caller {
 	for _, m := range ms {
@@ -0,0 +1,117 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/livechat/gokit/test/is"
+	"github.com/livechat/gokit/web/client"
+)
+
+func TestCallContextDeadline(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer s.Close()
+
+	var seen context.Context
+	spy := func(e client.Endpoint) client.Endpoint {
+		return client.EndpointFunc(func(r *http.Request) (*http.Response, error) {
+			seen = r.Context()
+			return e.Do(r)
+		})
+	}
+
+	c := client.NewCaller(s.Client(), client.JSONResponse(), spy)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var out map[string]interface{}
+	err := c.CallContext(ctx, client.Option{URL: s.URL, Method: http.MethodGet, Response: &out})
+
+	is.True(t, errors.Is(err, context.DeadlineExceeded), "deadline exceeded expected, got %v", err)
+	_, ok := seen.Deadline()
+	is.True(t, ok, "middleware expects deadline in request context")
+}
+
+func TestCallPayloadKeys(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"gokit"}`))
+	}))
+	defer s.Close()
+
+	// string keys used by other packages must not leak into the middlewares
+	ctx := context.WithValue(context.Background(), "out", "collision")
+
+	var out struct{ Name string }
+	c := client.NewCaller(s.Client(), client.JSONRequest(), client.JSONResponse())
+	is.Ok(t, c.CallContext(ctx, client.Option{URL: s.URL, Response: &out}))
+	is.Equal(t, "gokit", out.Name)
+}
+
+func TestOptionURL(t *testing.T) {
+	var path, query, header string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, query, header = r.URL.EscapedPath(), r.URL.RawQuery, r.Header.Get("X-Region")
+	}))
+	defer s.Close()
+
+	m := client.NewMetricsRegistry()
+	var called bool
+	spy := func(e client.Endpoint) client.Endpoint {
+		return client.EndpointFunc(func(r *http.Request) (*http.Response, error) {
+			called = true
+			return e.Do(r)
+		})
+	}
+
+	c := client.NewCaller(s.Client(), client.Metrics(m))
+	is.Ok(t, c.Call(client.Option{
+		URL:    s.URL + "/customers/{id}/notes?limit=10",
+		Params: map[string]string{"id": "a/b c"},
+		Query: struct {
+			Search string    `query:"query"`
+			Tags   []string  `query:"tag"`
+			Page   int       `query:"page,omitempty"`
+			Since  time.Time `query:"-"`
+		}{Search: "john&co", Tags: []string{"x", "y"}},
+		Header:      http.Header{"X-Region": {"fra"}},
+		Middlewares: []client.Middleware{spy},
+	}))
+
+	is.Equal(t, "/customers/a%2Fb%20c/notes", path)
+	is.Equal(t, "limit=10&query=john%26co&tag=x&tag=y", query)
+	is.Equal(t, "fra", header)
+	is.True(t, called, "per call middleware should be called")
+
+	var b strings.Builder
+	m.WriteTo(&b)
+	is.True(t, strings.Contains(b.String(), `route="/customers/:id/notes"`), "route template expected:\n%s", b.String())
+
+	err := c.Call(client.Option{URL: s.URL + "/customers/{id}"})
+	is.True(t, err != nil, "missing parameter should fail")
+}
+
+func TestOptionTimeout(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer s.Close()
+
+	c := client.NewCaller(s.Client())
+	err := c.Call(client.Option{URL: s.URL, Timeout: 20 * time.Millisecond})
+	is.True(t, errors.Is(err, context.DeadlineExceeded), "deadline exceeded expected, got %v", err)
+}
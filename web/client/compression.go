@@ -0,0 +1,190 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// ErrDecompressedTooLarge is returned when reading response which decompresses
+// to more than CompressionConfig.MaxDecompressed bytes.
+var ErrDecompressedTooLarge = errors.New("compression: decompressed body too large")
+
+// CompressionConfig configures Compression, zero values are replaced with defaults.
+type CompressionConfig struct {
+	// Threshold is minimum size of request body which is compressed (default 1KB).
+	Threshold int64
+	// Level of gzip compression (default gzip.DefaultCompression).
+	Level int
+	// MaxDecompressed limits size of decompressed response body (default 32MB).
+	MaxDecompressed int64
+}
+
+// Compression gzips request bodies encoded by Request which are above threshold,
+// and decompresses gzip or deflate responses. It has to be listed before
+// Response, Logging and Trace, so they see decompressed body.
+func Compression(c CompressionConfig) Middleware {
+	if c.Threshold <= 0 {
+		c.Threshold = 1 << 10
+	}
+	if c.Level == 0 {
+		c.Level = gzip.DefaultCompression
+	}
+	if c.MaxDecompressed <= 0 {
+		c.MaxDecompressed = 32 << 20
+	}
+
+	return func(e Endpoint) Endpoint {
+		return EndpointFunc(func(r *http.Request) (*http.Response, error) {
+			if err := c.compress(r); err != nil {
+				return nil, err
+			}
+
+			// setting header disables transparent decompression of http.Transport
+			if r.Header.Get("Accept-Encoding") == "" {
+				r.Header.Set("Accept-Encoding", "gzip, deflate")
+			}
+
+			res, err := e.Do(r)
+			if err != nil || res == nil {
+				return res, err
+			}
+
+			return res, c.decompress(res)
+		})
+	}
+}
+
+// compress replaces replayable request body with gzipped one.
+func (c CompressionConfig) compress(r *http.Request) error {
+	if r.GetBody == nil || r.ContentLength < c.Threshold || r.Header.Get("Content-Encoding") != "" {
+		return nil
+	}
+
+	body, err := r.GetBody()
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	b := &bytes.Buffer{}
+	w, err := gzip.NewWriterLevel(b, c.Level)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	// body which does not shrink is sent as is
+	if int64(b.Len()) >= r.ContentLength {
+		return nil
+	}
+
+	data := b.Bytes()
+	r.Header.Set("Content-Encoding", "gzip")
+	r.ContentLength = int64(len(data))
+	r.Body = ioutil.NopCloser(bytes.NewReader(data))
+	r.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(data)), nil
+	}
+
+	return nil
+}
+
+// decompress replaces body of gzip or deflate response with decompressing reader.
+func (c CompressionConfig) decompress(res *http.Response) error {
+	if res.Body == nil || res.Body == http.NoBody {
+		return nil
+	}
+
+	var (
+		rd  io.Reader
+		err error
+		enc = strings.ToLower(strings.TrimSpace(res.Header.Get("Content-Encoding")))
+	)
+
+	switch enc {
+	case "gzip", "x-gzip":
+		rd, err = gzip.NewReader(res.Body)
+	case "deflate":
+		rd, err = inflate(res.Body)
+	default:
+		return nil
+	}
+
+	if err == io.EOF {
+		rd, err = http.NoBody, nil
+	}
+	if err != nil {
+		res.Body.Close()
+		return fmt.Errorf("compression: %s: %w", enc, err)
+	}
+
+	res.Body = &decompressed{r: rd, c: res.Body, left: c.MaxDecompressed}
+	res.Header.Del("Content-Encoding")
+	res.Header.Del("Content-Length")
+	res.ContentLength = -1
+	res.Uncompressed = true
+
+	return nil
+}
+
+// inflate reads deflate body, which should be zlib stream, but servers
+// are often sending raw deflate data.
+func inflate(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	h, err := br.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+
+	if h[0]&0x0f == 8 && (uint16(h[0])<<8|uint16(h[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
+
+type decompressed struct {
+	r    io.Reader
+	c    io.Closer
+	left int64
+}
+
+func (d *decompressed) Read(p []byte) (int, error) {
+	if d.left <= 0 {
+		// body of exactly max size is fine
+		var one [1]byte
+		n, err := d.r.Read(one[:])
+		if n > 0 {
+			return 0, ErrDecompressedTooLarge
+		}
+		return 0, err
+	}
+
+	if int64(len(p)) > d.left {
+		p = p[:d.left]
+	}
+	n, err := d.r.Read(p)
+	d.left -= int64(n)
+
+	return n, err
+}
+
+func (d *decompressed) Close() error {
+	if c, ok := d.r.(io.Closer); ok {
+		c.Close()
+	}
+	return d.c.Close()
+}
@@ -0,0 +1,78 @@
+package client_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/livechat/gokit/test/is"
+	"github.com/livechat/gokit/web/client"
+)
+
+func TestCompression(t *testing.T) {
+	var encoding, accept string
+	var received map[string]string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding, accept = r.Header.Get("Content-Encoding"), r.Header.Get("Accept-Encoding")
+
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		b, _ := ioutil.ReadAll(zr)
+		received = map[string]string{"text": string(b)}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		zw.Write([]byte(`{"size":` + r.URL.Query().Get("size") + `}`))
+		zw.Close()
+	}))
+	defer s.Close()
+
+	c := client.NewCaller(s.Client(),
+		client.Compression(client.CompressionConfig{Threshold: 100}),
+		client.JSONResponse(),
+		client.JSONRequest(),
+		client.ResponseError(nil),
+	)
+
+	var out struct{ Size int }
+	text := strings.Repeat("livechat ", 100)
+	is.Ok(t, c.Call(client.Option{
+		URL:      s.URL + "?size=42",
+		Method:   "POST",
+		Request:  map[string]string{"text": text},
+		Response: &out,
+	}))
+
+	is.Equal(t, "gzip", encoding)
+	is.Equal(t, "gzip, deflate", accept)
+	is.True(t, strings.Contains(received["text"], text), "request should be decompressed by server")
+	is.Equal(t, 42, out.Size)
+}
+
+func TestCompressionLimit(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		zw.Write(bytes.Repeat([]byte{0}, 1<<20))
+		zw.Close()
+	}))
+	defer s.Close()
+
+	c := client.NewCaller(s.Client(),
+		client.Compression(client.CompressionConfig{MaxDecompressed: 1 << 10}),
+		client.Response("", client.TextDecode),
+	)
+
+	var out []byte
+	err := c.Call(client.Option{URL: s.URL, Response: &out})
+	is.True(t, errors.Is(err, client.ErrDecompressedTooLarge), "limit expected, got %v", err)
+}
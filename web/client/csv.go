@@ -0,0 +1,276 @@
+package client
+
+import (
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CSVRecord receives rows decoded by CsvDecode, in the same order as they are sent.
+type CSVRecord interface {
+	Row(v map[string]string)
+}
+
+// CSVError describes row which can not be decoded.
+type CSVError struct {
+	Line   int
+	Column string
+	Err    error
+}
+
+func (e *CSVError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("csv: line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("csv: line %d, column %q: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *CSVError) Unwrap() error { return e.Err }
+
+// CsvDecode is RFC 4180 decoder of csv document with header row. Rows are streamed
+// in order to CSVRecord, to callback func(T) error, to channel of T or appended to
+// pointer of slice. Channel is closed when decoding ends, but not when it's given
+// as Option.Response to Response middleware, then its owner closes it.
+// T might be a map[string]string or a struct, which fields are mapped by `csv:"name"` tags.
+func CsvDecode(r io.Reader, v interface{}) error {
+	d := NewCSVDecoder(r)
+
+	if rec, ok := v.(CSVRecord); ok {
+		for {
+			row := map[string]string{}
+			if err := d.Decode(&row); err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			rec.Row(row)
+		}
+	}
+
+	s, err := newSink(v, nil)
+	if err != nil {
+		return fmt.Errorf("csv: type %T does not implement Row(map[string]string): %w", v, err)
+	}
+	defer s.close()
+
+	for {
+		e := reflect.New(s.elem)
+		if err := d.Decode(e.Interface()); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if err := s.put(e.Elem()); err != nil {
+			return stopped(err)
+		}
+	}
+}
+
+// Deprecated: CsvDecodeNative is replaced by CsvDecode.
+func CsvDecodeNative(r io.Reader, v interface{}) error { return CsvDecode(r, v) }
+
+// Deprecated: CsvDecodeScannerAsync is replaced by CsvDecode, which keeps order of rows
+// and handles quoted fields correctly.
+func CsvDecodeScannerAsync(r io.Reader, v interface{}) error { return CsvDecode(r, v) }
+
+// CSVDecoder reads csv document row by row, first row is a header with column names.
+type CSVDecoder struct {
+	r      *csv.Reader
+	header []string
+	err    error
+}
+
+func NewCSVDecoder(r io.Reader) *CSVDecoder {
+	c := csv.NewReader(r)
+	c.FieldsPerRecord = -1
+
+	return &CSVDecoder{r: c}
+}
+
+// Header returns column names read from first row.
+func (d *CSVDecoder) Header() ([]string, error) {
+	if d.header != nil || d.err != nil {
+		return d.header, d.err
+	}
+
+	h, err := d.r.Read()
+	if err != nil {
+		d.err = err
+		return nil, err
+	}
+
+	if len(h) > 0 {
+		h[0] = strings.TrimPrefix(h[0], "\ufeff")
+	}
+	d.header = h
+
+	return h, nil
+}
+
+// Decode reads next row into v, which is a pointer to map[string]string or to
+// struct. It returns io.EOF when there are no more rows.
+func (d *CSVDecoder) Decode(v interface{}) error {
+	h, err := d.Header()
+	if err != nil {
+		return err
+	}
+
+	rec, err := d.r.Read()
+	if err != nil {
+		return err
+	}
+
+	line, _ := d.r.FieldPos(0)
+	if len(rec) != len(h) {
+		return &CSVError{Line: line, Err: fmt.Errorf("expects %d fields, has %d", len(h), len(rec))}
+	}
+
+	switch o := v.(type) {
+	case *map[string]string:
+		if *o == nil {
+			*o = make(map[string]string, len(h))
+		}
+		for i, k := range h {
+			(*o)[k] = rec[i]
+		}
+		return nil
+	case map[string]string:
+		for i, k := range h {
+			o[k] = rec[i]
+		}
+		return nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &CSVError{Line: line, Err: fmt.Errorf("expects pointer, has %T", v)}
+	}
+
+	rv = rv.Elem()
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return &CSVError{Line: line, Err: fmt.Errorf("expects struct or map[string]string, has %T", v)}
+	}
+
+	fields := csvFields(rv.Type())
+	for i, k := range h {
+		f, ok := fields[k]
+		if !ok {
+			continue
+		}
+
+		if err := setField(rv.FieldByIndex(f), rec[i]); err != nil {
+			l, _ := d.r.FieldPos(i)
+			return &CSVError{Line: l, Column: k, Err: err}
+		}
+	}
+
+	return nil
+}
+
+var csvCache sync.Map
+
+// csvFields maps column names into struct field indexes.
+func csvFields(t reflect.Type) map[string][]int {
+	if f, ok := csvCache.Load(t); ok {
+		return f.(map[string][]int)
+	}
+
+	fields := map[string][]int{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("csv"); ok {
+			if tag = strings.Split(tag, ",")[0]; tag == "-" {
+				continue
+			} else if tag != "" {
+				name = tag
+			}
+		}
+		fields[name] = f.Index
+	}
+
+	csvCache.Store(t, fields)
+	return fields
+}
+
+var textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+func setField(f reflect.Value, s string) error {
+	if f.Kind() == reflect.Ptr {
+		if s == "" {
+			return nil
+		}
+		if f.IsNil() {
+			f.Set(reflect.New(f.Type().Elem()))
+		}
+		f = f.Elem()
+	}
+
+	if f.CanAddr() && f.Addr().Type().Implements(textUnmarshaler) {
+		return f.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	if s == "" && f.Kind() != reflect.String {
+		f.Set(reflect.Zero(f.Type()))
+		return nil
+	}
+
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if f.Type() == reflect.TypeOf(time.Duration(0)) {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return err
+			}
+			f.SetInt(int64(d))
+			return nil
+		}
+		n, err := strconv.ParseInt(s, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetFloat(n)
+	default:
+		return errors.New("unsupported field type " + f.Type().String())
+	}
+
+	return nil
+}
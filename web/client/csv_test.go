@@ -0,0 +1,61 @@
+package client_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/livechat/gokit/test/is"
+	"github.com/livechat/gokit/web/client"
+)
+
+const exportCSV = "\ufefflicense,name,agents\n" +
+	"1,\"Acme, Inc.\",3\n" +
+	"2,\"Multi\nline \"\"quoted\"\"\",5\n" +
+	"3,Plain,7\n"
+
+type rows []map[string]string
+
+func (r *rows) Row(v map[string]string) { *r = append(*r, v) }
+
+func TestCsvDecodeRecordsInOrder(t *testing.T) {
+	var out rows
+	is.Ok(t, client.CsvDecode(strings.NewReader(exportCSV), &out))
+
+	is.Equal(t, 3, len(out))
+	is.Equal(t, "Acme, Inc.", out[0]["name"])
+	is.Equal(t, "Multi\nline \"quoted\"", out[1]["name"])
+	is.Equal(t, "3", out[2]["license"])
+}
+
+func TestCsvDecodeStructs(t *testing.T) {
+	type license struct {
+		ID     int    `csv:"license"`
+		Name   string `csv:"name"`
+		Agents uint   `csv:"agents"`
+	}
+
+	var out []license
+	is.Ok(t, client.CsvDecode(strings.NewReader(exportCSV), &out))
+	is.Equal(t, []license{{1, "Acme, Inc.", 3}, {2, "Multi\nline \"quoted\"", 5}, {3, "Plain", 7}}, out)
+
+	var ids []int
+	err := client.CsvDecode(strings.NewReader(exportCSV), func(l license) error {
+		ids = append(ids, l.ID)
+		return nil
+	})
+	is.Ok(t, err)
+	is.Equal(t, []int{1, 2, 3}, ids)
+}
+
+func TestCsvDecodeErrorLine(t *testing.T) {
+	var out []struct {
+		Agents int `csv:"agents"`
+	}
+	err := client.CsvDecode(strings.NewReader("agents\n1\nmany\n"), &out)
+
+	var cerr *client.CSVError
+	is.True(t, errors.As(err, &cerr), "CSVError expected, got %v", err)
+	is.Equal(t, 3, cerr.Line)
+	is.Equal(t, "agents", cerr.Column)
+}
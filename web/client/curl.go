@@ -0,0 +1,119 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// CurlConfig configures DumpCurlWith.
+type CurlConfig struct {
+	// Redactor masks secrets of dumped command, zero value masks nothing.
+	Redactor Redactor
+	// Always dumps every request, not only failed ones.
+	Always bool
+}
+
+// DumpCurl logs failed requests, which ended with error or non 2xx status, as
+// curl commands with secrets masked by DefaultRedactor.
+func DumpCurl(log Logger) Middleware {
+	return DumpCurlWith(log, CurlConfig{Redactor: DefaultRedactor})
+}
+
+// DumpCurlWith logs requests as curl commands with HTTP.curl label. Body is
+// taken from Request middleware, so request with streamed body (ie. multipart)
+// is dumped without it.
+func DumpCurlWith(log Logger, c CurlConfig) Middleware {
+	return func(e Endpoint) Endpoint {
+		return EndpointFunc(func(r *http.Request) (*http.Response, error) {
+			res, err := e.Do(r)
+
+			failed := err != nil || res == nil || res.StatusCode < 200 || res.StatusCode >= 300
+			if failed || c.Always {
+				log("HTTP.curl", "%s", c.command(r))
+			}
+
+			return res, err
+		})
+	}
+}
+
+// command renders request as copy-pasteable curl command.
+func (c CurlConfig) command(r *http.Request) string {
+	body, header, streamed := curlBody(r)
+
+	cmd := []string{"curl"}
+	if r.Method != "" && (r.Method != http.MethodGet || len(body) > 0) {
+		cmd = append(cmd, "-X", r.Method)
+	}
+	cmd = append(cmd, shellQuote(c.Redactor.URL(r.URL)))
+
+	header = c.Redactor.Header(header)
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		for _, v := range header[k] {
+			cmd = append(cmd, "-H", shellQuote(k+": "+v))
+		}
+	}
+
+	if len(body) > 0 {
+		body = c.Redactor.Body(header.Get("Content-Type"), body)
+		cmd = append(cmd, "--data-raw", shellQuote(string(body)))
+	}
+
+	s := strings.Join(cmd, " ")
+	if streamed {
+		s += " # streamed body is not dumped"
+	}
+
+	return s
+}
+
+// curlBody reads replayable request body, gzipped body is decompressed, so
+// the command is readable.
+func curlBody(r *http.Request) ([]byte, http.Header, bool) {
+	header := r.Header.Clone()
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, header, false
+	}
+	if r.GetBody == nil {
+		return nil, header, true
+	}
+
+	rc, err := r.GetBody()
+	if err != nil {
+		return nil, header, true
+	}
+	defer rc.Close()
+
+	b, err := ioutil.ReadAll(rc)
+	if err != nil {
+		return nil, header, true
+	}
+
+	if header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return b, header, false
+		}
+		if d, err := ioutil.ReadAll(zr); err == nil {
+			header.Del("Content-Encoding")
+			return d, header, false
+		}
+	}
+
+	return b, header, false
+}
+
+// shellQuote wraps s in single quotes, so it's passed to curl as is.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,54 @@
+package client_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/livechat/gokit/test/is"
+	"github.com/livechat/gokit/web/client"
+)
+
+func TestDumpCurl(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		}
+	}))
+	defer s.Close()
+
+	var lines []string
+	log := func(label string, args ...interface{}) {
+		lines = append(lines, fmt.Sprintf(args[0].(string), args[1:]...))
+	}
+
+	c := client.NewCaller(s.Client(),
+		client.JSONRequest(),
+		client.Authorization("Bearer secret-token"),
+		client.DumpCurl(log),
+	)
+
+	is.Ok(t, c.Call(client.Option{URL: s.URL + "/ok", Method: "POST", Request: map[string]string{"name": "x"}}))
+	is.Equal(t, 0, len(lines))
+
+	is.Ok(t, c.Call(client.Option{
+		URL:     s.URL + "/fail?password=secret-pass",
+		Method:  "POST",
+		Request: map[string]string{"name": "it's", "secret": "secret-value"},
+	}))
+	is.Equal(t, 1, len(lines))
+
+	cmd := lines[0]
+	is.True(t, strings.HasPrefix(cmd, "curl -X POST '"+s.URL+"/fail?password=%5BREDACTED%5D'"), "unexpected command: %s", cmd)
+	is.True(t, strings.Contains(cmd, `-H 'Authorization: [REDACTED]'`), "authorization should be masked: %s", cmd)
+	is.True(t, strings.Contains(cmd, `-H 'Content-Type: application/json'`), "content type expected: %s", cmd)
+	is.True(t, strings.Contains(cmd, `--data-raw '{"name":"it'\''s","secret":"[REDACTED]"}'`), "body expected: %s", cmd)
+	is.True(t, !strings.Contains(cmd, "secret-"), "secrets should be masked: %s", cmd)
+
+	lines = nil
+	c = client.NewCaller(s.Client(), client.DumpCurlWith(log, client.CurlConfig{Always: true}))
+	is.Ok(t, c.Call(client.Option{URL: s.URL + "/ok"}))
+	is.Equal(t, []string{"curl '" + s.URL + "/ok'"}, lines)
+}
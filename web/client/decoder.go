@@ -3,13 +3,13 @@ package client
 import (
 	"bufio"
 	"bytes"
-	"encoding/csv"
 	"encoding/json"
-	"errors"
+	"encoding/xml"
+	"fmt"
 	"io"
+	"io/ioutil"
+	"net/url"
 	"reflect"
-	"strings"
-	"sync"
 )
 
 // Decoder is reading output of Endpoint::Do and then transform it to given v
@@ -20,114 +20,89 @@ func JsonDecode(r io.Reader, v interface{}) error {
 	return json.NewDecoder(r).Decode(v)
 }
 
-func CsvDecodeNative(r io.Reader, v interface{}) error {
-	e, ok := v.(CSVRecord)
-	if !ok {
-		return errors.New("csv: type " + reflect.TypeOf(v).String() + " does" +
-			" not implement Row(map[string]string)")
-	}
+// XmlDecode is golang default transformation of xml document (r) into given struct (v)
+func XmlDecode(r io.Reader, v interface{}) error {
+	return xml.NewDecoder(r).Decode(v)
+}
 
-	c := csv.NewReader(r)
+// FormDecode reads url encoded form (r) into *url.Values or *map[string]string (v)
+func FormDecode(r io.Reader, v interface{}) error {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
 
-	// read header
-	h, err := c.Read()
+	q, err := url.ParseQuery(string(bytes.TrimSpace(b)))
 	if err != nil {
 		return err
 	}
 
-	// read rows
-	for {
-		rec, err := c.Read()
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			return errors.New("csv: unexpected error while reading content")
+	switch o := v.(type) {
+	case *url.Values:
+		*o = q
+	case *map[string]string:
+		if *o == nil {
+			*o = make(map[string]string, len(q))
 		}
-
-		l := map[string]string{}
-		for i, k := range h {
-			l[k] = rec[i]
+		for k := range q {
+			(*o)[k] = q.Get(k)
 		}
-
-		e.Row(l)
-	}
-	return nil
-}
-
-func CsvDecodeScannerAsync(r io.Reader, v interface{}) error {
-	e, ok := v.(CSVRecord)
-	if !ok {
-		return errors.New("csv: type " + reflect.TypeOf(v).String() + " does" +
-			" not implement Row(map[string]string)")
-	}
-
-	for l := range newCSV(r).ReadAsync() {
-		e.Row(l)
+	default:
+		return fmt.Errorf("form: type %T is not *url.Values nor *map[string]string", v)
 	}
 
 	return nil
 }
 
-// CSVRecord
-type CSVRecord interface {
-	Row(v map[string]string)
-}
+// TextDecode copies plain text (r) into *string, *[]byte or io.Writer (v)
+func TextDecode(r io.Reader, v interface{}) error {
+	switch o := v.(type) {
+	case io.Writer:
+		_, err := io.Copy(o, r)
+		return err
+	case *string:
+		b, err := ioutil.ReadAll(r)
+		*o = string(b)
+		return err
+	case *[]byte:
+		b, err := ioutil.ReadAll(r)
+		*o = b
+		return err
+	}
 
-type csvReader struct {
-	Scanner *bufio.Scanner
-	m       sync.Mutex
+	return fmt.Errorf("text: type %T is not *string, *[]byte nor io.Writer", v)
 }
 
-func (r csvReader) ReadAsync() <-chan map[string]string {
-	c := make(chan map[string]string)
-
-	var wg sync.WaitGroup
-	r.Scanner.Scan()
-	t := r.Scanner.Text()
-	h := strings.Split(t, `,`)
-
-	for r.Scanner.Scan() {
-		wg.Add(1)
-		go func(b string) {
-			defer wg.Done()
-			o := strings.Split(b, `,`)
-			x := make(map[string]string)
-			for i, k := range h {
-				k = strings.Replace(k, `"`, "", -1)
-				v := strings.Replace(o[i], `"`, "", -1)
-				x[k] = v
-			}
-			c <- x
-		}(r.Scanner.Text())
+// NdjsonDecode reads newline delimited json (r), every line is decoded separately
+// and passed to callback func(T) error, sent to channel of T or appended to pointer
+// of slice (v). Channel is closed when decoding ends, but not when it's given as
+// Option.Response to Response middleware, then its owner closes it.
+func NdjsonDecode(r io.Reader, v interface{}) error {
+	s, err := newSink(v, nil)
+	if err != nil {
+		return fmt.Errorf("ndjson: type %T: %w", v, err)
 	}
+	defer s.close()
 
-	go func() {
-		wg.Wait()
-		close(c)
-	}()
-
-	return c
-}
-
-func (r csvReader) Read() ([][]byte, error) {
-	if r.Scanner.Scan() {
-		b := r.Scanner.Bytes()
-		l := len(b)
-		if l <= 2 {
-			return nil, errors.New("malformed csv")
+	br := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		b, err := br.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return err
 		}
 
-		return bytes.Split(b[1:l-1], []byte(`","`)), nil
-	}
-	if err := r.Scanner.Err(); err != nil {
-		return nil, err
-	}
-	return nil, io.EOF
-}
+		if l := bytes.TrimSpace(b); len(l) > 0 {
+			e := reflect.New(s.elem)
+			if derr := json.Unmarshal(l, e.Interface()); derr != nil {
+				return fmt.Errorf("ndjson: line %d: %w", line, derr)
+			}
+			if perr := s.put(e.Elem()); perr != nil {
+				return stopped(perr)
+			}
+		}
 
-func newCSV(r io.Reader) csvReader {
-	return csvReader{
-		Scanner: bufio.NewScanner(r),
+		if err == io.EOF {
+			return nil
+		}
 	}
 }
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// Dedupe merges concurrent GET and HEAD requests with the same url and
+// Authorization header into single upstream call. Every waiting request gets
+// its own copy of response, so it should be listed before middlewares which
+// read or decode response body.
+func Dedupe() Middleware {
+	var (
+		mu      sync.Mutex
+		flights = map[string]*flight{}
+	)
+
+	return func(e Endpoint) Endpoint {
+		return EndpointFunc(func(r *http.Request) (*http.Response, error) {
+			if r.Method != "" && r.Method != http.MethodGet && r.Method != http.MethodHead {
+				return e.Do(r)
+			}
+
+			key := r.Method + " " + r.URL.String() + "\x00" + r.Header.Get("Authorization")
+
+			mu.Lock()
+			if f, ok := flights[key]; ok {
+				mu.Unlock()
+
+				select {
+				case <-r.Context().Done():
+					return nil, r.Context().Err()
+				case <-f.done:
+				}
+
+				// call cancelled by context of the first request is repeated
+				if isContextErr(f.err) && r.Context().Err() == nil {
+					return e.Do(r)
+				}
+				return f.response(r), f.err
+			}
+
+			f := &flight{done: make(chan struct{})}
+			flights[key] = f
+			mu.Unlock()
+
+			res, err := e.Do(r)
+			if res != nil {
+				var berr error
+				f.body, berr = capture(r, res)
+				f.status, f.statusCode, f.header = res.Status, res.StatusCode, res.Header.Clone()
+				if err == nil {
+					err = berr
+				}
+			}
+			f.err = err
+
+			mu.Lock()
+			delete(flights, key)
+			mu.Unlock()
+			close(f.done)
+
+			return res, err
+		})
+	}
+}
+
+type flight struct {
+	done       chan struct{}
+	status     string
+	statusCode int
+	header     http.Header
+	body       []byte
+	err        error
+}
+
+func (f *flight) response(r *http.Request) *http.Response {
+	if f.statusCode == 0 {
+		return nil
+	}
+
+	res := newResponse(r, f.statusCode, f.header, append([]byte(nil), f.body...))
+	res.Status = f.status
+	return res
+}
+
+func isContextErr(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
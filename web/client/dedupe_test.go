@@ -0,0 +1,47 @@
+package client_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/livechat/gokit/test/is"
+	"github.com/livechat/gokit/web/client"
+)
+
+func TestDedupe(t *testing.T) {
+	var calls int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(50 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"license":"42"}`))
+	}))
+	defer s.Close()
+
+	c := client.NewCaller(s.Client(), client.Dedupe(), client.JSONResponse())
+
+	var wg sync.WaitGroup
+	out := make([]map[string]string, 5)
+	errs := make([]error, 5)
+	for i := range out {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = c.Call(client.Option{URL: s.URL + "/licence/42", Response: &out[i]})
+		}(i)
+	}
+	wg.Wait()
+
+	is.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	for i := range out {
+		is.Ok(t, errs[i])
+		is.Equal(t, "42", out[i]["license"])
+	}
+
+	is.Ok(t, c.Call(client.Option{URL: s.URL + "/licence/42", Method: http.MethodPost}))
+	is.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,253 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrDownloadChanged is returned when resource changed while it was downloaded
+// and the part which was already written can not be discarded.
+var ErrDownloadChanged = errors.New("download: resource changed during download")
+
+// DownloadConfig configures Download, zero values are replaced with defaults.
+type DownloadConfig struct {
+	// Attempts is maximum number of requests, including resumed ones (default 5).
+	Attempts int
+	// Backoff is a pause before download is resumed (default 500ms).
+	Backoff time.Duration
+	// Progress is called after every write with number of written bytes and
+	// total size of resource, which is -1 when it's not known.
+	Progress func(written, total int64)
+}
+
+// Download streams body of resource given by o into w. When connection drops,
+// download is resumed with Range request validated by ETag or Last-Modified of
+// the resource. When resource changed meanwhile, download starts over if w might
+// be truncated (as *os.File), otherwise ErrDownloadChanged is returned. Caller
+// should not have middlewares which read response body, as Logging or Trace.
+func Download(ctx context.Context, c Caller, o Option, w io.Writer, cfg DownloadConfig) (int64, error) {
+	if cfg.Attempts <= 0 {
+		cfg.Attempts = 5
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = 500 * time.Millisecond
+	}
+
+	d := &download{w: w, cfg: cfg, total: -1}
+	var err error
+
+	for attempt := 1; attempt <= cfg.Attempts; attempt++ {
+		if attempt > 1 {
+			t := time.NewTimer(cfg.Backoff)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return d.written, ctx.Err()
+			case <-t.C:
+			}
+		}
+
+		err = d.fetch(ctx, c, o)
+		if err == nil {
+			return d.written, nil
+		}
+
+		if ctx.Err() != nil {
+			return d.written, ctx.Err()
+		}
+		if !d.resumable(err) {
+			return d.written, err
+		}
+	}
+
+	return d.written, err
+}
+
+// DownloadFile downloads resource into file at path, which is created or truncated.
+func DownloadFile(ctx context.Context, c Caller, o Option, path string, cfg DownloadConfig) (int64, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := Download(ctx, c, o, f, cfg)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	return n, err
+}
+
+// errIncomplete is returned when body ended before whole resource was read.
+var errIncomplete = errors.New("download: incomplete body")
+
+type download struct {
+	w       io.Writer
+	cfg     DownloadConfig
+	written int64
+	total   int64
+	etag    string
+	lastMod string
+}
+
+func (d *download) fetch(ctx context.Context, c Caller, o Option) error {
+	o.Method = http.MethodGet
+	o.Request, o.Response = nil, nil
+	o.Header = o.Header.Clone()
+	if o.Header == nil {
+		o.Header = http.Header{}
+	}
+
+	resumed := d.written > 0
+	if resumed {
+		o.Header.Set("Range", fmt.Sprintf("bytes=%d-", d.written))
+		if d.etag != "" {
+			o.Header.Set("If-Range", d.etag)
+		} else {
+			o.Header.Set("If-Range", d.lastMod)
+		}
+	}
+
+	var (
+		called bool
+		herr   error
+	)
+	hook := func(r *http.Request, res *http.Response) {
+		called = true
+		herr = d.read(r, res, resumed)
+	}
+
+	err := c.CallContext(withResponseHook(ctx, hook), o)
+	if herr != nil {
+		return herr
+	}
+	if err != nil {
+		return err
+	}
+	if !called {
+		return errIncomplete
+	}
+
+	return nil
+}
+
+// read checks response and copies its body into writer.
+func (d *download) read(r *http.Request, res *http.Response, resumed bool) error {
+	switch {
+	case res.StatusCode == http.StatusOK:
+		if resumed {
+			// range was ignored or resource changed
+			if err := d.restart(); err != nil {
+				return err
+			}
+		}
+		d.total = res.ContentLength
+		d.etag, d.lastMod = res.Header.Get("ETag"), res.Header.Get("Last-Modified")
+
+	case res.StatusCode == http.StatusPartialContent && resumed:
+		start, total, ok := contentRange(res.Header.Get("Content-Range"))
+		if !ok || start != d.written || (d.total >= 0 && total >= 0 && total != d.total) {
+			return fmt.Errorf("%w: unexpected range %q", ErrDownloadChanged, res.Header.Get("Content-Range"))
+		}
+		if etag := res.Header.Get("ETag"); etag != "" && d.etag != "" && etag != d.etag {
+			return fmt.Errorf("%w: etag %s != %s", ErrDownloadChanged, etag, d.etag)
+		}
+
+	default:
+		return newHTTPError(r, res, nil)
+	}
+
+	_, err := io.Copy(writerFunc(d.write), res.Body)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case d.total >= 0 && d.written < d.total:
+		return errIncomplete
+	case d.total >= 0 && d.written > d.total:
+		return fmt.Errorf("%w: got %d bytes of %d", ErrDownloadChanged, d.written, d.total)
+	}
+
+	return nil
+}
+
+func (d *download) write(p []byte) (int, error) {
+	n, err := d.w.Write(p)
+	d.written += int64(n)
+	if d.cfg.Progress != nil {
+		d.cfg.Progress(d.written, d.total)
+	}
+	return n, err
+}
+
+// restart discards written part, when writer might be truncated.
+func (d *download) restart() error {
+	f, ok := d.w.(interface {
+		io.Seeker
+		Truncate(int64) error
+	})
+	if !ok {
+		return ErrDownloadChanged
+	}
+
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	d.written = 0
+	return nil
+}
+
+// resumable reports if download might be continued after err.
+func (d *download) resumable(err error) bool {
+	if errors.Is(err, ErrDownloadChanged) {
+		return false
+	}
+	if code := StatusCode(err); code != 0 && code < 500 {
+		return false
+	}
+	// resource without validator can not be safely resumed
+	return d.written == 0 || d.etag != "" || d.lastMod != ""
+}
+
+// contentRange parses "bytes start-end/total" header, total is -1 when it's unknown.
+func contentRange(h string) (start, total int64, ok bool) {
+	if !strings.HasPrefix(h, "bytes ") {
+		return 0, 0, false
+	}
+
+	p := strings.SplitN(strings.TrimPrefix(h, "bytes "), "/", 2)
+	if len(p) != 2 {
+		return 0, 0, false
+	}
+
+	r := strings.SplitN(p[0], "-", 2)
+	start, err := strconv.ParseInt(r[0], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	total = -1
+	if p[1] != "*" {
+		if total, err = strconv.ParseInt(p[1], 10, 64); err != nil {
+			return 0, 0, false
+		}
+	}
+
+	return start, total, true
+}
+
+type writerFunc func([]byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) { return f(p) }
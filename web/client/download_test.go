@@ -0,0 +1,102 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/livechat/gokit/test/is"
+	"github.com/livechat/gokit/web/client"
+)
+
+func TestDownload(t *testing.T) {
+	data := bytes.Repeat([]byte("license,region\n42,dal\n"), 5000)
+	var (
+		calls  int32
+		ranges []string
+	)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		w.Header().Set("ETag", `"v1"`)
+		if atomic.AddInt32(&calls, 1) == 1 {
+			// connection drops in the middle of body
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+			w.Write(data[:len(data)/3])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		http.ServeContent(w, r, "export.csv", time.Time{}, bytes.NewReader(data))
+	}))
+	defer s.Close()
+
+	var progress int64
+	c := client.NewCaller(s.Client(), client.ResponseError(nil))
+	b := &bytes.Buffer{}
+	n, err := client.Download(context.Background(), c, client.Option{URL: s.URL}, b, client.DownloadConfig{
+		Backoff:  time.Millisecond,
+		Progress: func(written, total int64) { progress = written },
+	})
+
+	is.Ok(t, err)
+	is.Equal(t, int64(len(data)), n)
+	is.Equal(t, int64(len(data)), progress)
+	is.True(t, bytes.Equal(data, b.Bytes()), "downloaded data differs")
+	is.Equal(t, 2, len(ranges))
+	is.Equal(t, "bytes="+strconv.Itoa(len(data)/3)+"-", ranges[1])
+}
+
+func TestDownloadFileChanged(t *testing.T) {
+	var calls int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		data := bytes.Repeat([]byte{byte('0' + n)}, 10000)
+		w.Header().Set("ETag", `"v`+strconv.Itoa(int(n))+`"`)
+		if n == 1 {
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+			w.Write(data[:100])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		http.ServeContent(w, r, "export.csv", time.Time{}, bytes.NewReader(data))
+	}))
+	defer s.Close()
+
+	c := client.NewCaller(s.Client(), client.ResponseError(nil))
+	cfg := client.DownloadConfig{Backoff: time.Millisecond}
+
+	// file is truncated when resource changed
+	path := filepath.Join(t.TempDir(), "export.csv")
+	_, err := client.DownloadFile(context.Background(), c, client.Option{URL: s.URL}, path, cfg)
+	is.Ok(t, err)
+	b, _ := ioutil.ReadFile(path)
+	is.True(t, bytes.Equal(bytes.Repeat([]byte("2"), 10000), b), "file should have new version only")
+
+	// buffer can not be truncated
+	atomic.StoreInt32(&calls, 0)
+	_, err = client.Download(context.Background(), c, client.Option{URL: s.URL}, &bytes.Buffer{}, cfg)
+	is.True(t, errors.Is(err, client.ErrDownloadChanged), "changed resource expected, got %v", err)
+}
+
+func TestDownloadResponseWithoutRequest(t *testing.T) {
+	e := client.EndpointFunc(func(r *http.Request) (*http.Response, error) {
+		w := httptest.NewRecorder()
+		w.WriteHeader(http.StatusNotFound)
+		return w.Result(), nil
+	})
+
+	c := client.NewCaller(e)
+	_, err := client.Download(context.Background(), c, client.Option{URL: "http://files.test/export.csv"}, &bytes.Buffer{}, client.DownloadConfig{})
+
+	var herr *client.HTTPError
+	is.True(t, errors.As(err, &herr), "http error expected, got %v", err)
+	is.Equal(t, http.StatusNotFound, herr.StatusCode)
+	is.Equal(t, "http://files.test/export.csv", herr.URL)
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// MaxErrorBody is a maximum number of response body bytes kept by HTTPError.
+var MaxErrorBody = 4 << 10
+
+// HTTPError describes unsuccessful http exchange, it is returned by ResponseError
+// middleware and might be unwrapped from error with errors.As.
+type HTTPError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Status     string
+	Header     http.Header
+	// Body is a snapshot of response body, capped to MaxErrorBody bytes.
+	Body []byte
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("[%s] %s [%s]", e.Method, e.URL, e.Status)
+}
+
+func newHTTPError(r *http.Request, res *http.Response, body []byte) *HTTPError {
+	if len(body) > MaxErrorBody {
+		body = body[:MaxErrorBody]
+	}
+
+	status := res.Status
+	if status == "" {
+		status = fmt.Sprintf("%d %s", res.StatusCode, http.StatusText(res.StatusCode))
+	}
+
+	return &HTTPError{
+		Method:     r.Method,
+		URL:        r.URL.String(),
+		StatusCode: res.StatusCode,
+		Status:     status,
+		Header:     res.Header.Clone(),
+		Body:       append([]byte(nil), body...),
+	}
+}
+
+// StatusCode returns status of HTTPError wrapped by err, or 0 when there is none.
+func StatusCode(err error) int {
+	var e *HTTPError
+	if errors.As(err, &e) {
+		return e.StatusCode
+	}
+	return 0
+}
+
+// IsBadRequest reports if err was caused by 400 Bad Request response.
+func IsBadRequest(err error) bool { return StatusCode(err) == http.StatusBadRequest }
+
+// IsUnauthorized reports if err was caused by 401 Unauthorized response.
+func IsUnauthorized(err error) bool { return StatusCode(err) == http.StatusUnauthorized }
+
+// IsForbidden reports if err was caused by 403 Forbidden response.
+func IsForbidden(err error) bool { return StatusCode(err) == http.StatusForbidden }
+
+// IsNotFound reports if err was caused by 404 Not Found response.
+func IsNotFound(err error) bool { return StatusCode(err) == http.StatusNotFound }
+
+// IsConflict reports if err was caused by 409 Conflict response.
+func IsConflict(err error) bool { return StatusCode(err) == http.StatusConflict }
+
+// IsUnprocessable reports if err was caused by 422 Unprocessable Entity response.
+func IsUnprocessable(err error) bool { return StatusCode(err) == http.StatusUnprocessableEntity }
+
+// IsTooManyRequests reports if err was caused by 429 Too Many Requests response.
+func IsTooManyRequests(err error) bool { return StatusCode(err) == http.StatusTooManyRequests }
+
+// IsServerError reports if err was caused by any 5xx response.
+func IsServerError(err error) bool { return StatusCode(err) >= 500 }
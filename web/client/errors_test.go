@@ -0,0 +1,33 @@
+package client_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/livechat/gokit/test/is"
+	"github.com/livechat/gokit/web/client"
+)
+
+func TestResponseErrorIsTyped(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "abc")
+		http.Error(w, "license not found", http.StatusNotFound)
+	}))
+	defer s.Close()
+
+	c := client.NewCaller(s.Client(), client.ResponseError(nil))
+	err := c.Call(client.Option{URL: s.URL + "/licence/1", Method: http.MethodGet})
+
+	var herr *client.HTTPError
+	is.True(t, errors.As(fmt.Errorf("wrapped: %w", err), &herr), "HTTPError expected, got %v", err)
+	is.Equal(t, http.StatusNotFound, herr.StatusCode)
+	is.Equal(t, http.MethodGet, herr.Method)
+	is.Equal(t, s.URL+"/licence/1", herr.URL)
+	is.Equal(t, "abc", herr.Header.Get("X-Request-Id"))
+	is.Equal(t, "license not found\n", string(herr.Body))
+	is.True(t, client.IsNotFound(err), "not found expected")
+	is.True(t, !client.IsUnauthorized(err), "unauthorized is not expected")
+}
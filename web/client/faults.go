@@ -0,0 +1,239 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FaultKind is a kind of failure injected by Faults.
+type FaultKind int
+
+const (
+	// FaultLatency delays request by Fault.Latency.
+	FaultLatency FaultKind = iota
+	// FaultConnection fails request with *url.Error wrapping ErrInjectedFault, endpoint is not called.
+	FaultConnection
+	// FaultStatus answers with Fault.Status, endpoint is not called.
+	FaultStatus
+	// FaultTruncate cuts response body in half, reading it ends with io.ErrUnexpectedEOF.
+	FaultTruncate
+	// FaultMalformed breaks json (or any other) response body.
+	FaultMalformed
+)
+
+var faultKinds = []string{"latency", "connection", "status", "truncate", "malformed"}
+
+func (k FaultKind) String() string {
+	if k < 0 || int(k) >= len(faultKinds) {
+		return "unknown"
+	}
+	return faultKinds[k]
+}
+
+// ErrInjectedFault is returned by requests failed with FaultConnection.
+var ErrInjectedFault = errors.New("fault: injected connection error")
+
+// Fault describes single failure and requests which it affects.
+type Fault struct {
+	Kind FaultKind
+	// Probability of fault in range [0, 1].
+	Probability float64
+	// Host is path.Match pattern of request host, empty one matches any host.
+	Host string
+	// Path is path.Match pattern of request path, empty one matches any path.
+	Path string
+	// Latency added by FaultLatency (default 1s).
+	Latency time.Duration
+	// Status returned by FaultStatus (default 503).
+	Status int
+}
+
+func (f Fault) matches(r *http.Request) bool {
+	if f.Host != "" {
+		if ok, _ := path.Match(f.Host, r.URL.Host); !ok {
+			return false
+		}
+	}
+	if f.Path != "" {
+		if ok, _ := path.Match(f.Path, r.URL.Path); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// FaultConfig configures Faults.
+type FaultConfig struct {
+	Faults []Fault
+	// Rand returns numbers in range [0, 1) deciding if fault happens (default rand.Float64).
+	Rand func() float64
+}
+
+// Faults injects failures into requests, so handling of misbehaving services
+// might be tested without real outages. It has to be listed first, so it's
+// close to the endpoint as a real failure would be. Faults of the request are
+// drawn independently and applied in order of config.
+func Faults(c FaultConfig) Middleware {
+	if c.Rand == nil {
+		var mu sync.Mutex
+		rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+		c.Rand = func() float64 {
+			mu.Lock()
+			defer mu.Unlock()
+			return rnd.Float64()
+		}
+	}
+
+	return func(e Endpoint) Endpoint {
+		return EndpointFunc(func(r *http.Request) (*http.Response, error) {
+			var body []Fault
+			for _, f := range c.Faults {
+				if !f.matches(r) || c.Rand() >= f.Probability {
+					continue
+				}
+
+				switch f.Kind {
+				case FaultLatency:
+					d := f.Latency
+					if d <= 0 {
+						d = time.Second
+					}
+					t := time.NewTimer(d)
+					select {
+					case <-r.Context().Done():
+						t.Stop()
+						return nil, r.Context().Err()
+					case <-t.C:
+					}
+				case FaultConnection:
+					// wrapped as transport error, so it's retried as a real one
+					return nil, &url.Error{Op: r.Method, URL: r.URL.String(), Err: ErrInjectedFault}
+				case FaultStatus:
+					status := f.Status
+					if status == 0 {
+						status = http.StatusServiceUnavailable
+					}
+					return newResponse(r, status, http.Header{"X-Fault": {f.Kind.String()}}, nil), nil
+				case FaultTruncate, FaultMalformed:
+					body = append(body, f)
+				}
+			}
+
+			res, err := e.Do(r)
+			if err != nil || res == nil || len(body) == 0 {
+				return res, err
+			}
+
+			b, berr := capture(r, res)
+			if berr != nil {
+				return res, err
+			}
+
+			var rerr error
+			for _, f := range body {
+				switch f.Kind {
+				case FaultTruncate:
+					b, rerr = b[:len(b)/2], io.ErrUnexpectedEOF
+				case FaultMalformed:
+					b = append(b[:len(b)/2:len(b)/2], "\x00}"...)
+				}
+			}
+
+			res.Header.Set("X-Fault", body[len(body)-1].Kind.String())
+			res.Header.Del("Content-Length")
+			res.ContentLength = -1
+			res.Body = &capturedBody{Reader: bytes.NewReader(b), data: b, err: rerr}
+
+			return res, err
+		})
+	}
+}
+
+// FaultsFromEnv reads FaultConfig from environment variable, so faults might be
+// switched on without code changes. Faults are separated by semicolon and
+// described as kind:probability[:latency or status][@host[/path]], ie.
+//
+//	CLIENT_FAULTS="latency:0.5:300ms@api.livechat.com;status:0.1:429@*/v2/*;malformed:0.05"
+//
+// Empty variable gives config without faults.
+func FaultsFromEnv(name string) (FaultConfig, error) {
+	var c FaultConfig
+
+	for _, s := range strings.Split(os.Getenv(name), ";") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+
+		f, err := parseFault(s)
+		if err != nil {
+			return c, fmt.Errorf("%s: %q: %w", name, s, err)
+		}
+		c.Faults = append(c.Faults, f)
+	}
+
+	return c, nil
+}
+
+func parseFault(s string) (Fault, error) {
+	var f Fault
+
+	if p := strings.Index(s, "@"); p != -1 {
+		target := s[p+1:]
+		s = s[:p]
+		if i := strings.Index(target, "/"); i != -1 {
+			target, f.Path = target[:i], target[i:]
+		}
+		f.Host = target
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return f, errors.New("expected kind:probability[:argument]")
+	}
+
+	kind := -1
+	for i, k := range faultKinds {
+		if k == parts[0] {
+			kind = i
+		}
+	}
+	if kind == -1 {
+		return f, fmt.Errorf("unknown fault %q", parts[0])
+	}
+	f.Kind = FaultKind(kind)
+
+	p, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil || p < 0 || p > 1 {
+		return f, fmt.Errorf("probability %q out of range [0, 1]", parts[1])
+	}
+	f.Probability = p
+
+	if len(parts) == 3 {
+		switch f.Kind {
+		case FaultLatency:
+			if f.Latency, err = time.ParseDuration(parts[2]); err != nil {
+				return f, err
+			}
+		case FaultStatus:
+			if f.Status, err = strconv.Atoi(parts[2]); err != nil {
+				return f, err
+			}
+		default:
+			return f, fmt.Errorf("fault %s has no argument", f.Kind)
+		}
+	}
+
+	return f, nil
+}
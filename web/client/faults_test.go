@@ -0,0 +1,90 @@
+package client_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/livechat/gokit/test/is"
+	"github.com/livechat/gokit/web/client"
+)
+
+func TestFaults(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"license":"42","region":"dal"}`))
+	}))
+	defer s.Close()
+
+	always := func() float64 { return 0 }
+	call := func(f client.Fault) error {
+		c := client.NewCaller(s.Client(),
+			client.Faults(client.FaultConfig{Faults: []client.Fault{f}, Rand: always}),
+			client.ResponseError(nil),
+			client.JSONResponse(),
+		)
+		var out map[string]string
+		return c.Call(client.Option{URL: s.URL + "/v2/licence", Response: &out})
+	}
+
+	is.True(t, errors.Is(call(client.Fault{Kind: client.FaultConnection, Probability: 1}), client.ErrInjectedFault),
+		"connection error expected")
+	is.True(t, client.IsTooManyRequests(call(client.Fault{Kind: client.FaultStatus, Probability: 1, Status: 429})),
+		"status expected")
+	is.True(t, errors.Is(call(client.Fault{Kind: client.FaultTruncate, Probability: 1}), io.ErrUnexpectedEOF),
+		"truncated body expected")
+	is.Err(t, call(client.Fault{Kind: client.FaultMalformed, Probability: 1}), "malformed body expected")
+	is.Ok(t, call(client.Fault{Kind: client.FaultConnection, Probability: 0}))
+	is.Ok(t, call(client.Fault{Kind: client.FaultConnection, Probability: 1, Path: "/v3/*"}))
+
+	start := time.Now()
+	is.Ok(t, call(client.Fault{Kind: client.FaultLatency, Probability: 1, Latency: 30 * time.Millisecond}))
+	is.True(t, time.Since(start) >= 30*time.Millisecond, "latency expected")
+}
+
+func TestFaultsRetried(t *testing.T) {
+	var calls int
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer s.Close()
+
+	// only the first attempt fails
+	var draws int
+	first := func() float64 {
+		draws++
+		if draws == 1 {
+			return 0
+		}
+		return 1
+	}
+
+	c := client.NewCaller(s.Client(),
+		client.Faults(client.FaultConfig{Faults: []client.Fault{{Kind: client.FaultConnection, Probability: 1}}, Rand: first}),
+		client.Retry(client.RetryPolicy{Attempts: 2, MinBackoff: time.Millisecond}),
+	)
+
+	is.Ok(t, c.Call(client.Option{URL: s.URL}))
+	is.Equal(t, 1, calls)
+	is.Equal(t, 2, draws)
+}
+
+func TestFaultsFromEnv(t *testing.T) {
+	t.Setenv("CLIENT_FAULTS_TEST", "latency:0.5:300ms@api.livechat.com; status:0.1:429@*/v2/*;malformed:1")
+
+	c, err := client.FaultsFromEnv("CLIENT_FAULTS_TEST")
+	is.Ok(t, err)
+	is.Equal(t, 3, len(c.Faults))
+	is.Equal(t, client.Fault{Kind: client.FaultLatency, Probability: 0.5, Latency: 300 * time.Millisecond,
+		Host: "api.livechat.com"}, c.Faults[0])
+	is.Equal(t, client.Fault{Kind: client.FaultStatus, Probability: 0.1, Status: 429, Host: "*", Path: "/v2/*"},
+		c.Faults[1])
+	is.Equal(t, client.Fault{Kind: client.FaultMalformed, Probability: 1}, c.Faults[2])
+
+	t.Setenv("CLIENT_FAULTS_TEST", "explode:0.5")
+	_, err = client.FaultsFromEnv("CLIENT_FAULTS_TEST")
+	is.Err(t, err, "unknown fault expected")
+}
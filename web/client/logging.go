@@ -0,0 +1,146 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// LogConfig configures LoggingWith.
+type LogConfig struct {
+	// Redactor masks secrets of logged headers, urls and bodies, zero value
+	// masks nothing.
+	Redactor Redactor
+	// MaxBody is maximum number of logged body bytes, zero value does not
+	// truncate bodies and negative one disables logging of them.
+	MaxBody int
+	// JSON logs single structured record of exchange with HTTP.exchange label,
+	// instead of separate line per exchange part.
+	JSON bool
+}
+
+// DefaultLogConfig is used by Logging.
+var DefaultLogConfig = LogConfig{Redactor: DefaultRedactor, MaxBody: 1 << 10}
+
+// Logging logs requests and responses with secrets masked by DefaultRedactor.
+func Logging(log Logger) Middleware {
+	return LoggingWith(log, DefaultLogConfig)
+}
+
+// exchange is structured log record of single request and response.
+type exchange struct {
+	Method          string      `json:"method"`
+	URL             string      `json:"url"`
+	RequestHeaders  http.Header `json:"request_headers,omitempty"`
+	RequestBody     string      `json:"request_body,omitempty"`
+	Status          int         `json:"status,omitempty"`
+	ResponseHeaders http.Header `json:"response_headers,omitempty"`
+	ResponseSize    int         `json:"response_size"`
+	ResponseBody    string      `json:"response_body,omitempty"`
+	Duration        float64     `json:"duration_ms"`
+	Error           string      `json:"error,omitempty"`
+}
+
+// LoggingWith logs requests and responses as configured by c.
+func LoggingWith(log Logger, c LogConfig) Middleware {
+	return func(e Endpoint) Endpoint {
+		return EndpointFunc(func(r *http.Request) (*http.Response, error) {
+			start := time.Now()
+			res, err := e.Do(r)
+
+			x := exchange{
+				Method:         r.Method,
+				URL:            c.Redactor.URL(r.URL),
+				RequestHeaders: c.Redactor.Header(r.Header),
+				RequestBody:    c.body(requestBody(r)),
+				Duration:       float64(time.Since(start).Microseconds()) / 1000,
+			}
+
+			var berr error
+			if res != nil {
+				var o []byte
+				o, berr = capture(r, res)
+
+				x.Status = res.StatusCode
+				x.ResponseHeaders = c.Redactor.Header(res.Header)
+				x.ResponseSize = len(o)
+				x.ResponseBody = c.body(res.Header.Get("Content-Type"), o)
+			}
+
+			switch {
+			case err != nil:
+				x.Error = err.Error()
+			case berr != nil:
+				x.Error = berr.Error()
+			}
+
+			if c.JSON {
+				b, _ := json.Marshal(x)
+				log("HTTP.exchange", "%s", b)
+				return res, err
+			}
+
+			log("HTTP.request.url", "[%s] %s", x.Method, x.URL)
+			log("HTTP.request.headers", "%v", x.RequestHeaders)
+			if x.RequestBody != "" {
+				log("HTTP.request.body", "%s", x.RequestBody)
+			}
+
+			if res != nil {
+				log("HTTP.response.status", "%v", res.Status)
+				log("HTTP.response.headers", "%v", x.ResponseHeaders)
+				log("HTTP.response.size", "%.2fKB", float64(x.ResponseSize)/1024)
+				log("HTTP.response.body", "%s\n", x.ResponseBody)
+			}
+
+			if x.Error != "" {
+				log("HTTP.response.error", "%s", x.Error)
+			}
+
+			return res, err
+		})
+	}
+}
+
+// body redacts and truncates logged body.
+func (c LogConfig) body(contentType string, b []byte) string {
+	if c.MaxBody < 0 || len(b) == 0 {
+		return ""
+	}
+
+	b = c.Redactor.Body(contentType, b)
+	if c.MaxBody > 0 && len(b) > c.MaxBody {
+		return fmt.Sprintf("%s...(%d bytes truncated)", b[:c.MaxBody], len(b)-c.MaxBody)
+	}
+
+	return string(b)
+}
+
+// requestBody returns content type and encoded request body when it might be
+// read again, or json representation of Option.Request otherwise, so it's
+// redacted as json whatever the request encoding is.
+func requestBody(r *http.Request) (string, []byte) {
+	// compressed body is not readable, so request value is logged instead
+	if r.GetBody != nil && r.Header.Get("Content-Encoding") == "" {
+		if rc, err := r.GetBody(); err == nil {
+			defer rc.Close()
+			if b, err := ioutil.ReadAll(rc); err == nil {
+				return r.Header.Get("Content-Type"), b
+			}
+		}
+	}
+
+	in := requestValue(r)
+	if in == nil {
+		return "", nil
+	}
+
+	// value which can not be redacted is not logged
+	b, err := json.Marshal(in)
+	if err != nil {
+		return "", nil
+	}
+	return "application/json", b
+}
@@ -0,0 +1,96 @@
+package client_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/livechat/gokit/test/is"
+	"github.com/livechat/gokit/web/client"
+)
+
+func TestLogging(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"secret-token","text":"` + strings.Repeat("x", 100) + `"}`))
+	}))
+	defer s.Close()
+
+	var lines []string
+	log := func(label string, args ...interface{}) {
+		lines = append(lines, label+" "+fmt.Sprintf(args[0].(string), args[1:]...))
+	}
+
+	c := client.NewCaller(s.Client(),
+		client.JSONResponse(),
+		client.JSONRequest(),
+		client.Header("Authorization", "Bearer secret-token"),
+		client.LoggingWith(log, client.LogConfig{Redactor: client.DefaultRedactor, MaxBody: 60}),
+	)
+
+	var out map[string]string
+	is.Ok(t, c.Call(client.Option{
+		URL:      s.URL + "/login?password=secret-password&user=john",
+		Method:   "POST",
+		Request:  map[string]string{"password": "secret-password", "login": "john"},
+		Response: &out,
+	}))
+
+	all := strings.Join(lines, "\n")
+	is.True(t, !strings.Contains(all, "secret-"), "secrets should be redacted:\n%s", all)
+	is.True(t, strings.Contains(all, "user=john"), "query should be logged:\n%s", all)
+	is.True(t, strings.Contains(all, "bytes truncated"), "body should be truncated:\n%s", all)
+}
+
+func TestLoggingJSON(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer s.Close()
+
+	var lines []string
+	log := func(label string, args ...interface{}) {
+		lines = append(lines, fmt.Sprintf(args[0].(string), args[1:]...))
+	}
+
+	c := client.NewCaller(s.Client(),
+		client.LoggingWith(log, client.LogConfig{Redactor: client.DefaultRedactor, JSON: true}),
+	)
+	is.Ok(t, c.Call(client.Option{URL: s.URL + "?token=abc"}))
+	is.Equal(t, 1, len(lines))
+
+	var x map[string]interface{}
+	is.Ok(t, json.Unmarshal([]byte(lines[0]), &x))
+	is.Equal(t, float64(http.StatusTeapot), x["status"])
+	is.True(t, strings.Contains(x["url"].(string), "token=%5BREDACTED%5D"), "token should be redacted: %v", x["url"])
+}
+
+func TestLoggingCompressedForm(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer s.Close()
+
+	var lines []string
+	log := func(label string, args ...interface{}) {
+		lines = append(lines, label+" "+fmt.Sprintf(args[0].(string), args[1:]...))
+	}
+
+	c := client.NewCaller(s.Client(),
+		client.LoggingWith(log, client.LogConfig{Redactor: client.DefaultRedactor}),
+		client.Compression(client.CompressionConfig{Threshold: 1}),
+		client.FormRequest(),
+	)
+
+	is.Ok(t, c.Call(client.Option{
+		URL:     s.URL + "/login",
+		Method:  "POST",
+		Request: url.Values{"password": {"secret-password"}, "login": {"john"}, "note": {strings.Repeat("x", 2048)}},
+	}))
+
+	all := strings.Join(lines, "\n")
+	is.True(t, !strings.Contains(all, "secret-"), "secrets should be redacted:\n%s", all)
+	is.True(t, strings.Contains(all, "john"), "body should be logged:\n%s", all)
+}
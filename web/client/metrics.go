@@ -0,0 +1,224 @@
+package client
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are upper bounds (in seconds) of request duration histogram.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// MetricsRegistry collects counters and latency histograms of outbound calls and
+// exposes them in Prometheus text format, so it might be mounted as http.Handler.
+type MetricsRegistry struct {
+	// Namespace prefixes every metric name (default http_client).
+	Namespace string
+	// Route returns route template of request used as label, by default
+	// identifiers such as numbers, uuids or emails are replaced with :id.
+	Route func(*http.Request) string
+
+	mu       sync.Mutex
+	buckets  []float64
+	series   map[metricLabels]*series
+	inFlight map[string]int64
+}
+
+type metricLabels struct{ host, method, route, status string }
+
+type series struct {
+	count   uint64
+	sum     float64
+	buckets []uint64
+}
+
+// NewMetricsRegistry creates registry with given histogram buckets, DefaultBuckets
+// are used when none are given.
+func NewMetricsRegistry(buckets ...float64) *MetricsRegistry {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	b := append([]float64(nil), buckets...)
+	sort.Float64s(b)
+
+	return &MetricsRegistry{
+		Namespace: "http_client",
+		Route:     routeTemplate,
+		buckets:   b,
+		series:    map[metricLabels]*series{},
+		inFlight:  map[string]int64{},
+	}
+}
+
+// Metrics counts requests and measures their latency, labeled by host, method,
+// route template and status class (2xx, 4xx, 5xx... or error).
+func Metrics(m *MetricsRegistry) Middleware {
+	return func(e Endpoint) Endpoint {
+		return EndpointFunc(func(r *http.Request) (*http.Response, error) {
+			host := r.URL.Host
+			m.track(host, 1)
+			t := time.Now()
+
+			res, err := e.Do(r)
+
+			m.track(host, -1)
+			m.observe(metricLabels{
+				host:   host,
+				method: r.Method,
+				route:  m.Route(r),
+				status: statusClass(res, err),
+			}, time.Since(t))
+
+			return res, err
+		})
+	}
+}
+
+func (m *MetricsRegistry) track(host string, d int64) {
+	m.mu.Lock()
+	m.inFlight[host] += d
+	m.mu.Unlock()
+}
+
+func (m *MetricsRegistry) observe(l metricLabels, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.series[l]
+	if !ok {
+		s = &series{buckets: make([]uint64, len(m.buckets))}
+		m.series[l] = s
+	}
+
+	v := d.Seconds()
+	s.count++
+	s.sum += v
+	for i, b := range m.buckets {
+		if v <= b {
+			s.buckets[i]++
+		}
+	}
+}
+
+// ServeHTTP writes metrics in Prometheus text exposition format.
+func (m *MetricsRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+// WriteTo writes metrics in Prometheus text exposition format.
+func (m *MetricsRegistry) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cw := &countWriter{w: bufio.NewWriter(w)}
+	ns := m.Namespace
+
+	keys := make([]metricLabels, 0, len(m.series))
+	for k := range m.series {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.host != b.host {
+			return a.host < b.host
+		}
+		if a.method != b.method {
+			return a.method < b.method
+		}
+		if a.route != b.route {
+			return a.route < b.route
+		}
+		return a.status < b.status
+	})
+
+	fmt.Fprintf(cw, "# HELP %s_requests_total Total number of outbound HTTP requests.\n", ns)
+	fmt.Fprintf(cw, "# TYPE %s_requests_total counter\n", ns)
+	for _, k := range keys {
+		fmt.Fprintf(cw, "%s_requests_total{%s} %d\n", ns, k.String(), m.series[k].count)
+	}
+
+	fmt.Fprintf(cw, "# HELP %s_request_duration_seconds Latency of outbound HTTP requests.\n", ns)
+	fmt.Fprintf(cw, "# TYPE %s_request_duration_seconds histogram\n", ns)
+	for _, k := range keys {
+		s, l := m.series[k], k.String()
+		for i, b := range m.buckets {
+			fmt.Fprintf(cw, "%s_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n",
+				ns, l, strconv.FormatFloat(b, 'g', -1, 64), s.buckets[i])
+		}
+		fmt.Fprintf(cw, "%s_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", ns, l, s.count)
+		fmt.Fprintf(cw, "%s_request_duration_seconds_sum{%s} %s\n", ns, l, strconv.FormatFloat(s.sum, 'g', -1, 64))
+		fmt.Fprintf(cw, "%s_request_duration_seconds_count{%s} %d\n", ns, l, s.count)
+	}
+
+	hosts := make([]string, 0, len(m.inFlight))
+	for h := range m.inFlight {
+		hosts = append(hosts, h)
+	}
+	sort.Strings(hosts)
+
+	fmt.Fprintf(cw, "# HELP %s_requests_in_flight Number of outbound HTTP requests waiting for response.\n", ns)
+	fmt.Fprintf(cw, "# TYPE %s_requests_in_flight gauge\n", ns)
+	for _, h := range hosts {
+		fmt.Fprintf(cw, "%s_requests_in_flight{host=\"%s\"} %d\n", ns, escapeLabel(h), m.inFlight[h])
+	}
+
+	return cw.n, cw.w.Flush()
+}
+
+func (l metricLabels) String() string {
+	return fmt.Sprintf(`host="%s",method="%s",route="%s",status="%s"`,
+		escapeLabel(l.host), escapeLabel(l.method), escapeLabel(l.route), escapeLabel(l.status))
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(v string) string { return labelEscaper.Replace(v) }
+
+func statusClass(res *http.Response, err error) string {
+	if res == nil {
+		if err != nil {
+			return "error"
+		}
+		return "unknown"
+	}
+
+	return strconv.Itoa(res.StatusCode/100) + "xx"
+}
+
+var identifier = regexp.MustCompile(`^([0-9]+|[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|[0-9a-fA-F]{24,}|[^/]+@[^/]+)$`)
+
+// routeTemplate returns path template given by Option.URL, or replaces identifiers
+// in request path with :id.
+func routeTemplate(r *http.Request) string {
+	if route, ok := r.Context().Value(routeKey).(string); ok {
+		return route
+	}
+
+	parts := strings.Split(r.URL.Path, "/")
+	for i, p := range parts {
+		if identifier.MatchString(p) {
+			parts[i] = ":id"
+		}
+	}
+
+	return strings.Join(parts, "/")
+}
+
+type countWriter struct {
+	w *bufio.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,48 @@
+package client_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/livechat/gokit/test/is"
+	"github.com/livechat/gokit/web/client"
+	"github.com/livechat/gokit/web/server"
+)
+
+func TestMetricsExposition(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/missing") {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer s.Close()
+
+	m := client.NewMetricsRegistry(0.5, 1)
+	c := client.NewCaller(s.Client(), client.Metrics(m))
+	is.Ok(t, c.Call(client.Option{URL: s.URL + "/crm/customers/123"}))
+	is.Ok(t, c.Call(client.Option{URL: s.URL + "/crm/customers/456"}))
+	is.Ok(t, c.Call(client.Option{URL: s.URL + "/configuration/operator/john@doe.com/missing"}))
+
+	r := server.New().Mount("/metrics", m)
+	ms := httptest.NewServer(r)
+	defer ms.Close()
+
+	res, err := http.Get(ms.URL + "/metrics")
+	is.Ok(t, err)
+	b, _ := ioutil.ReadAll(res.Body)
+	out := string(b)
+
+	host := strings.TrimPrefix(s.URL, "http://")
+	for _, line := range []string{
+		"# TYPE http_client_requests_total counter",
+		`http_client_requests_total{host="` + host + `",method="GET",route="/crm/customers/:id",status="2xx"} 2`,
+		`http_client_requests_total{host="` + host + `",method="GET",route="/configuration/operator/:id/missing",status="4xx"} 1`,
+		`http_client_request_duration_seconds_bucket{host="` + host + `",method="GET",route="/crm/customers/:id",status="2xx",le="+Inf"} 2`,
+		`http_client_requests_in_flight{host="` + host + `"} 0`,
+	} {
+		is.True(t, strings.Contains(out, line+"\n"), "line %q expected in:\n%s", line, out)
+	}
+}
@@ -67,7 +67,7 @@ func FormRequest() Middleware {
 func Request(kind string, en Encoder) Middleware {
 	return func(e Endpoint) Endpoint {
 		return EndpointFunc(func(r *http.Request) (*http.Response, error) {
-			input := r.Context().Value("in")
+			input := requestValue(r)
 			if input == nil {
 				return e.Do(r)
 			}
@@ -87,8 +87,14 @@ func Request(kind string, en Encoder) Middleware {
 				return res, ern
 			}
 
-			r.ContentLength = int64(len(b.String()))
-			r.Body = ioutil.NopCloser(b)
+			// keep encoded body, so it might be replayed by middlewares
+			// which are sending same request more than once
+			data := b.Bytes()
+			r.ContentLength = int64(len(data))
+			r.Body = ioutil.NopCloser(bytes.NewReader(data))
+			r.GetBody = func() (io.ReadCloser, error) {
+				return ioutil.NopCloser(bytes.NewReader(data)), nil
+			}
 
 			return e.Do(r)
 		})
@@ -99,15 +105,20 @@ func JSONResponse() Middleware {
 	return Response("application/json", JsonDecode)
 }
 
+// CSVResponse decodes CSV response body into Option.Response. Body is read into
+// memory without size limit, unless it's set by BodyLimit, so rows sent to
+// callback or channel are decoded from in-memory copy of the whole body.
 func CSVResponse() Middleware {
-	return Response("text/csv", CsvDecodeScannerAsync)
+	return Response("text/csv", CsvDecode)
 }
 
-// Response
+// Response decodes body of response with content type kind into Option.Response.
+// Body is read into memory without size limit, unless it's set by BodyLimit, so
+// memory use grows with size of the response (see DefaultBodyConfig).
 func Response(kind string, d Decoder) Middleware {
 	return func(e Endpoint) Endpoint {
 		return EndpointFunc(func(r *http.Request) (*http.Response, error) {
-			output := r.Context().Value("out")
+			output := responseValue(r)
 			if output == nil {
 				return e.Do(r)
 			}
@@ -121,25 +132,22 @@ func Response(kind string, d Decoder) Middleware {
 				return res, err
 			}
 
-			//if res.Title.Get("Content-type") != kind {
-			//	return res, errors.New("wrong response content-type header, expected " + kind + " has: " + res.Title.Get("Content-type"))
-			//}
-
-			// copy out body stream to s
-			b := &bytes.Buffer{}
-			s := io.TeeReader(res.Body, b)
-			res.Body = ioutil.NopCloser(b)
-
-			// decode out into given value
-			if err := d(s, output); err != nil {
-				return res, err
-			}
-
-			return res, err
+			return res, decodeBody(r, res, d, output)
 		})
 	}
 }
 
+// decodeBody decodes response body into output and leaves copy of the body in
+// response, so it might be read again by other middlewares.
+func decodeBody(r *http.Request, res *http.Response, d Decoder, output interface{}) error {
+	b, err := capture(r, res)
+	if err != nil {
+		return err
+	}
+
+	return d(bytes.NewReader(b), sendFunc(r, output))
+}
+
 // Authorization
 func Authorization(token string) Middleware {
 	return func(e Endpoint) Endpoint {
@@ -182,73 +190,14 @@ func Header(k, v string) Middleware {
 	}
 }
 
-// Logging
-func Logging(log Logger) Middleware {
-
-	return func(e Endpoint) Endpoint {
-		return EndpointFunc(func(r *http.Request) (*http.Response, error) {
-			res, err := e.Do(r)
-
-			log("HTTP.request.url", "[%s] %s", r.Method, r.URL)
-			log("HTTP.request.headers", "%v", r.Header)
-			in := r.Context().Value("in")
-			if in != nil {
-				log("HTTP.request.body", "%v", in)
-			}
-
-			if res != nil {
-				//copy out body stream to s
-				b := &bytes.Buffer{}
-				s := io.TeeReader(res.Body, b)
-				res.Body = ioutil.NopCloser(b)
-
-				o, _ := ioutil.ReadAll(s)
-				log("HTTP.response.status", "%v", res.Status)
-				log("HTTP.response.headers", "%v", res.Header)
-				log("HTTP.response.size", "%.2fKB", float64(len(o))/1024)
-				log("HTTP.response.body", "%s\n", string(o))
-			}
-
-			return res, err
-		})
-	}
-}
-
 // Trace gives detailed information about HTTP call, it will look and measure
 // all the HTTP parts such as tcp connection, dns lookup, tlc handshaking and
 // body transfer.
 func Trace(fn func(TraceInfo), log Logger) Middleware {
 	return func(e Endpoint) Endpoint {
 		return EndpointFunc(func(r *http.Request) (*http.Response, error) {
-			var t0, t1, t2, t3, t4 time.Time
-			t0 = time.Now()
-
-			trace := &httptrace.ClientTrace{
-				DNSStart: func(_ httptrace.DNSStartInfo) {
-					t0 = time.Now()
-				},
-				DNSDone: func(_ httptrace.DNSDoneInfo) {
-					t1 = time.Now()
-				},
-				ConnectStart: func(_, _ string) {
-					if t1.IsZero() {
-						// connecting to IP
-						t1 = time.Now()
-					}
-				},
-				ConnectDone: func(net, addr string, err error) {
-					if err != nil {
-						log("HTTP.trace.details", "unable connect to host %v: %v", addr, err)
-					}
-					t2 = time.Now()
-				},
-				GotConn: func(i httptrace.GotConnInfo) {
-					t3 = time.Now()
-				},
-				GotFirstResponseByte: func() { t4 = time.Now() },
-			}
-
-			r = r.WithContext(httptrace.WithClientTrace(r.Context(), trace))
+			ct := &connTrace{t0: time.Now()}
+			r = r.WithContext(httptrace.WithClientTrace(r.Context(), ct.hooks(log)))
 
 			res, err := e.Do(r)
 
@@ -258,45 +207,13 @@ func Trace(fn func(TraceInfo), log Logger) Middleware {
 			}
 
 			// in order to measure all LC timings, we need to drain whole
-			// body and copy new into res.Body again
-			b := &bytes.Buffer{}
-			s := io.TeeReader(res.Body, b)
-			res.Body = ioutil.NopCloser(b)
-			rb, _ := ioutil.ReadAll(s)
-
-			t5 := time.Now() // after read body
-
-			if t0.IsZero() {
-				// we skipped DNS
-				t0 = t1
-			}
-
-			if t0.IsZero() && t2.IsZero() {
-				t0 = t3
-				t1 = t3
-				t2 = t3
-			}
-
-			//connection timestamp
-			var t time.Time
-			if !t0.IsZero() {
-				t = t0
-			} else {
-				t = t3
+			// body, it's kept in res.Body for the next readers
+			rb, berr := capture(r, res)
+			if berr != nil {
+				log("HTTP.trace.details.error", berr.Error())
 			}
 
-			ti := TraceInfo{
-				Timestamp:        t,
-				DNSLookup:        t1.Sub(t0),
-				TCPConnection:    t2.Sub(t1),
-				TLSHandshake:     t3.Sub(t2),
-				ServerProcessing: t4.Sub(t3),
-				ContentTransfer:  t5.Sub(t4),
-				Total:            t5.Sub(t0),
-				Endpoint:         *r.URL,
-				Headers:          r.Header,
-				ContentSize:      len(rb),
-			}
+			ti := ct.info(r, time.Now(), len(rb))
 
 			token := r.Header.Get("Authorization")
 			if len(token) >= 8 {
@@ -330,7 +247,78 @@ func Trace(fn func(TraceInfo), log Logger) Middleware {
 	}
 }
 
-func ResponseError(fn func(int, []byte) error) Middleware {
+// connTrace measures phases of HTTP call with httptrace hooks.
+type connTrace struct {
+	t0, t1, t2, t3, t4 time.Time
+}
+
+func (c *connTrace) hooks(log Logger) *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(_ httptrace.DNSStartInfo) {
+			c.t0 = time.Now()
+		},
+		DNSDone: func(_ httptrace.DNSDoneInfo) {
+			c.t1 = time.Now()
+		},
+		ConnectStart: func(_, _ string) {
+			if c.t1.IsZero() {
+				// connecting to IP
+				c.t1 = time.Now()
+			}
+		},
+		ConnectDone: func(net, addr string, err error) {
+			if err != nil {
+				log("HTTP.trace.details", "unable connect to host %v: %v", addr, err)
+			}
+			c.t2 = time.Now()
+		},
+		GotConn: func(i httptrace.GotConnInfo) {
+			c.t3 = time.Now()
+		},
+		GotFirstResponseByte: func() { c.t4 = time.Now() },
+	}
+}
+
+// info returns timings of call which body was read at t5.
+func (c *connTrace) info(r *http.Request, t5 time.Time, size int) TraceInfo {
+	t0, t1, t2, t3, t4 := c.t0, c.t1, c.t2, c.t3, c.t4
+
+	if t0.IsZero() {
+		// we skipped DNS
+		t0 = t1
+	}
+
+	if t0.IsZero() && t2.IsZero() {
+		t0 = t3
+		t1 = t3
+		t2 = t3
+	}
+
+	//connection timestamp
+	var t time.Time
+	if !t0.IsZero() {
+		t = t0
+	} else {
+		t = t3
+	}
+
+	return TraceInfo{
+		Timestamp:        t,
+		DNSLookup:        t1.Sub(t0),
+		TCPConnection:    t2.Sub(t1),
+		TLSHandshake:     t3.Sub(t2),
+		ServerProcessing: t4.Sub(t3),
+		ContentTransfer:  t5.Sub(t4),
+		Total:            t5.Sub(t0),
+		Endpoint:         *r.URL,
+		Headers:          r.Header,
+		ContentSize:      size,
+	}
+}
+
+// ResponseError turns unsuccessful response into *HTTPError. When fn is given, it
+// might translate HTTPError into domain error, or return nil to ignore it.
+func ResponseError(fn func(*HTTPError) error) Middleware {
 	return func(e Endpoint) Endpoint {
 		return EndpointFunc(func(r *http.Request) (*http.Response, error) {
 			res, err := e.Do(r)
@@ -339,15 +327,16 @@ func ResponseError(fn func(int, []byte) error) Middleware {
 			}
 
 			if res.StatusCode < 200 || res.StatusCode >= 400 {
-				if fn != nil {
-					b := &bytes.Buffer{}
-					data, _ := ioutil.ReadAll(io.TeeReader(res.Body, b))
-					res.Body = ioutil.NopCloser(b)
+				// body of error response is not required, so read failure
+				// is ignored and HTTPError keeps whatever was read
+				data, _ := capture(r, res)
 
-					return res, fn(res.StatusCode, data)
+				herr := newHTTPError(r, res, data)
+				if fn != nil {
+					return res, fn(herr)
 				}
 
-				return res, fmt.Errorf("[%s] %s [%s]", r.Method, r.URL, res.Status)
+				return res, herr
 			}
 
 			return res, err
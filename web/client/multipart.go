@@ -0,0 +1,118 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Multipart describes multipart/form-data request body, it's given as Option.Request.
+type Multipart struct {
+	Fields url.Values
+	Files  []File
+}
+
+// File is uploaded as a part of multipart body, Reader is closed after upload
+// when it's an io.Closer.
+type File struct {
+	// Field is name of form field.
+	Field string
+	// Name is filename sent to server.
+	Name string
+	// ContentType of file (default application/octet-stream).
+	ContentType string
+	Reader      io.Reader
+}
+
+// MultipartRequest encodes Multipart given as Option.Request. Body is streamed
+// through a pipe while it's sent, so files are never kept in memory. Such body
+// can not be replayed, so it is not retried by Retry nor OAuth2. When used with
+// JSONRequest, MultipartRequest has to be listed after it.
+func MultipartRequest() Middleware {
+	return func(e Endpoint) Endpoint {
+		return EndpointFunc(func(r *http.Request) (*http.Response, error) {
+			var m *Multipart
+			switch v := requestValue(r).(type) {
+			case Multipart:
+				m = &v
+			case *Multipart:
+				m = v
+			default:
+				return e.Do(r)
+			}
+
+			pr, pw := io.Pipe()
+			w := multipart.NewWriter(pw)
+			go func() {
+				pw.CloseWithError(m.write(w))
+			}()
+
+			r.Header.Set("Content-Type", w.FormDataContentType())
+			r.ContentLength = -1
+			r.Body = pr
+			r.GetBody = nil
+
+			res, err := e.Do(r)
+			// unblock writer when transport did not read whole body
+			pr.Close()
+
+			return res, err
+		})
+	}
+}
+
+func (m *Multipart) write(w *multipart.Writer) error {
+	defer m.close()
+
+	keys := make([]string, 0, len(m.Fields))
+	for k := range m.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		for _, v := range m.Fields[k] {
+			if err := w.WriteField(k, v); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, f := range m.Files {
+		typ := f.ContentType
+		if typ == "" {
+			typ = "application/octet-stream"
+		}
+
+		h := textproto.MIMEHeader{}
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			quoteEscaper.Replace(f.Field), quoteEscaper.Replace(f.Name)))
+		h.Set("Content-Type", typ)
+
+		p, err := w.CreatePart(h)
+		if err != nil {
+			return err
+		}
+
+		if _, err := io.Copy(p, f.Reader); err != nil {
+			return fmt.Errorf("multipart: %s: %w", f.Name, err)
+		}
+	}
+
+	return w.Close()
+}
+
+func (m *Multipart) close() {
+	for _, f := range m.Files {
+		if c, ok := f.Reader.(io.Closer); ok {
+			c.Close()
+		}
+	}
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
@@ -0,0 +1,55 @@
+package client_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/livechat/gokit/test/is"
+	"github.com/livechat/gokit/web/client"
+)
+
+func TestMultipartRequest(t *testing.T) {
+	var (
+		name, typ, content, field string
+		length                    int64
+	)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		length = r.ContentLength
+		f, h, err := r.FormFile("upload")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		defer f.Close()
+
+		b, _ := ioutil.ReadAll(f)
+		name, typ, content = h.Filename, h.Header.Get("Content-Type"), string(b)
+		field = r.FormValue("title")
+	}))
+	defer s.Close()
+
+	c := client.NewCaller(s.Client(), client.ResponseError(nil), client.JSONRequest(), client.MultipartRequest())
+	is.Ok(t, c.Call(client.Option{
+		URL:    s.URL,
+		Method: "POST",
+		Request: client.Multipart{
+			Fields: url.Values{"title": {"report"}},
+			Files: []client.File{{
+				Field:       "upload",
+				Name:        `q"1.csv`,
+				ContentType: "text/csv",
+				Reader:      strings.NewReader("a,b\n1,2\n"),
+			}},
+		},
+	}))
+
+	is.Equal(t, int64(-1), length)
+	is.Equal(t, "report", field)
+	is.Equal(t, `q"1.csv`, name)
+	is.Equal(t, "text/csv", typ)
+	is.Equal(t, "a,b\n1,2\n", content)
+}
@@ -0,0 +1,147 @@
+package client
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// UnsupportedMediaTypeError is returned by Negotiate when there is no Decoder
+// registered for Content-Type of the response.
+type UnsupportedMediaTypeError struct {
+	ContentType string
+	Supported   []string
+}
+
+func (e *UnsupportedMediaTypeError) Error() string {
+	return fmt.Sprintf("unsupported response content-type %q, expected one of: %s",
+		e.ContentType, strings.Join(e.Supported, ", "))
+}
+
+// Decoders is a registry of Decoder for every media type.
+type Decoders struct {
+	mu    sync.RWMutex
+	types []string
+	m     map[string]Decoder
+}
+
+// DefaultDecoders is used by Negotiate middleware.
+var DefaultDecoders = NewDecoders()
+
+func init() {
+	DefaultDecoders.Register("application/json", JsonDecode)
+	DefaultDecoders.Register("application/x-ndjson", NdjsonDecode)
+	DefaultDecoders.Register("text/csv", CsvDecode)
+	DefaultDecoders.Register("application/xml", XmlDecode)
+	DefaultDecoders.Register("text/xml", XmlDecode)
+	DefaultDecoders.Register("application/x-www-form-urlencoded", FormDecode)
+	DefaultDecoders.Register("text/plain", TextDecode)
+}
+
+func NewDecoders() *Decoders {
+	return &Decoders{m: map[string]Decoder{}}
+}
+
+// Register sets Decoder for media type, such as application/json.
+func (d *Decoders) Register(mediaType string, dec Decoder) {
+	mediaType = strings.ToLower(mediaType)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.m[mediaType]; !ok {
+		d.types = append(d.types, mediaType)
+	}
+	d.m[mediaType] = dec
+}
+
+// Lookup finds Decoder for Content-Type header value. Structured syntax suffix
+// is respected, so application/problem+json is decoded as application/json.
+func (d *Decoders) Lookup(contentType string) (Decoder, error) {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, &UnsupportedMediaTypeError{ContentType: contentType, Supported: d.Types()}
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if dec, ok := d.m[mt]; ok {
+		return dec, nil
+	}
+
+	if i := strings.LastIndex(mt, "+"); i != -1 {
+		if dec, ok := d.m[mt[:strings.Index(mt, "/")+1]+mt[i+1:]]; ok {
+			return dec, nil
+		}
+	}
+
+	return nil, &UnsupportedMediaTypeError{
+		ContentType: contentType,
+		Supported:   append([]string(nil), d.types...),
+	}
+}
+
+// Types returns registered media types in order of registration.
+func (d *Decoders) Types() []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return append([]string(nil), d.types...)
+}
+
+// Accept builds Accept header value, earlier registered types are preferred.
+func (d *Decoders) Accept() string {
+	types := d.Types()
+	for i := range types {
+		if q := 10 - i; i > 0 && q > 0 {
+			types[i] += fmt.Sprintf(";q=0.%d", q)
+		} else if q <= 0 {
+			types[i] += ";q=0.1"
+		}
+	}
+
+	return strings.Join(types, ", ")
+}
+
+// Negotiate decodes response with DefaultDecoders chosen by its Content-Type.
+func Negotiate() Middleware {
+	return NegotiateWith(DefaultDecoders)
+}
+
+// NegotiateWith sends Accept header built from registered decoders and decodes
+// response with Decoder matching its Content-Type. *UnsupportedMediaTypeError is
+// returned instead of decoding content which is not understood. As Response, it
+// decodes in-memory copy of the body, which is limited only by BodyLimit.
+func NegotiateWith(d *Decoders) Middleware {
+	return func(e Endpoint) Endpoint {
+		return EndpointFunc(func(r *http.Request) (*http.Response, error) {
+			output := responseValue(r)
+			if output == nil {
+				return e.Do(r)
+			}
+
+			if r.Header.Get("Accept") == "" {
+				r.Header.Set("Accept", d.Accept())
+			}
+
+			res, err := e.Do(r)
+			if err != nil {
+				return res, err
+			}
+
+			if res.StatusCode == http.StatusNoContent || res.ContentLength == 0 {
+				return res, nil
+			}
+
+			dec, err := d.Lookup(res.Header.Get("Content-Type"))
+			if err != nil {
+				return res, err
+			}
+
+			return res, decodeBody(r, res, dec, output)
+		})
+	}
+}
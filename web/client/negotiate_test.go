@@ -0,0 +1,44 @@
+package client_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/livechat/gokit/test/is"
+	"github.com/livechat/gokit/web/client"
+)
+
+func TestNegotiate(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json":
+			w.Header().Set("Content-Type", "application/problem+json; charset=utf-8")
+			w.Write([]byte(`{"title":"gone"}`))
+		case "/ndjson":
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.Write([]byte("{\"id\":1}\n\n{\"id\":2}\n"))
+		default:
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte{0x89, 'P', 'N', 'G'})
+		}
+	}))
+	defer s.Close()
+
+	c := client.NewCaller(s.Client(), client.Negotiate())
+
+	var problem struct{ Title string }
+	is.Ok(t, c.Call(client.Option{URL: s.URL + "/json", Response: &problem}))
+	is.Equal(t, "gone", problem.Title)
+
+	var items []struct{ ID int }
+	is.Ok(t, c.Call(client.Option{URL: s.URL + "/ndjson", Response: &items}))
+	is.Equal(t, 2, len(items))
+	is.Equal(t, 2, items[1].ID)
+
+	var unsupported *client.UnsupportedMediaTypeError
+	err := c.Call(client.Option{URL: s.URL + "/png", Response: &problem})
+	is.True(t, errors.As(err, &unsupported), "UnsupportedMediaTypeError expected, got %v", err)
+	is.Equal(t, "image/png", unsupported.ContentType)
+}
@@ -0,0 +1,170 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Token is OAuth2 access token with optional refresh token.
+type Token struct {
+	AccessToken  string
+	TokenType    string
+	RefreshToken string
+	// Expiry is time when access token expires, zero value means it never does.
+	Expiry time.Time
+}
+
+// Valid reports if access token is set and it is not going to expire within leeway.
+func (t Token) Valid(leeway time.Duration) bool {
+	return t.AccessToken != "" && (t.Expiry.IsZero() || time.Now().Add(leeway).Before(t.Expiry))
+}
+
+// Authorization returns value of Authorization header.
+func (t Token) Authorization() string {
+	typ := t.TokenType
+	if typ == "" {
+		typ = "Bearer"
+	}
+	return typ + " " + t.AccessToken
+}
+
+// TokenSource gives access token for OAuth2 middleware.
+type TokenSource interface {
+	Token(context.Context) (Token, error)
+}
+
+// RefreshFunc exchanges refresh token for new token.
+type RefreshFunc func(ctx context.Context, refreshToken string) (Token, error)
+
+// ErrNoRefreshToken is returned when token expired and it can not be refreshed.
+var ErrNoRefreshToken = errors.New("oauth2: token expired and refresh token is not set")
+
+// RefreshingSource caches token and refreshes it shortly before it expires.
+// Concurrent callers share single refresh call.
+type RefreshingSource struct {
+	// Leeway is how long before expiry token is refreshed (default 1 minute).
+	Leeway time.Duration
+
+	mu      sync.Mutex
+	token   Token
+	refresh RefreshFunc
+	flight  *refreshCall
+}
+
+type refreshCall struct {
+	done  chan struct{}
+	token Token
+	err   error
+}
+
+// NewTokenSource creates TokenSource which starts with token t and uses refresh
+// to get new one when it is about to expire.
+func NewTokenSource(t Token, refresh RefreshFunc) *RefreshingSource {
+	return &RefreshingSource{Leeway: time.Minute, token: t, refresh: refresh}
+}
+
+// Token returns cached token, or waits for refreshed one when it is about to expire.
+func (s *RefreshingSource) Token(ctx context.Context) (Token, error) {
+	s.mu.Lock()
+	if s.token.Valid(s.Leeway) {
+		t := s.token
+		s.mu.Unlock()
+		return t, nil
+	}
+
+	c := s.flight
+	if c == nil {
+		if s.token.RefreshToken == "" || s.refresh == nil {
+			s.mu.Unlock()
+			return Token{}, ErrNoRefreshToken
+		}
+
+		c = &refreshCall{done: make(chan struct{})}
+		s.flight = c
+		go s.run(c, s.token.RefreshToken)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return Token{}, ctx.Err()
+	case <-c.done:
+		return c.token, c.err
+	}
+}
+
+// run refreshes token, it does not depend on context of any single caller,
+// as the result is shared by all of them.
+func (s *RefreshingSource) run(c *refreshCall, refreshToken string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	t, err := s.refresh(ctx, refreshToken)
+	if err == nil && t.RefreshToken == "" {
+		// refresh token is not rotated by server
+		t.RefreshToken = refreshToken
+	}
+
+	s.mu.Lock()
+	if err == nil {
+		s.token = t
+	}
+	s.flight = nil
+	s.mu.Unlock()
+
+	c.token, c.err = t, err
+	close(c.done)
+}
+
+// Invalidate forces refresh of token t, when it is still the cached one. It is
+// used after server rejects token, so tokens refreshed meanwhile are not lost.
+func (s *RefreshingSource) Invalidate(t Token) {
+	s.mu.Lock()
+	if s.token.AccessToken == t.AccessToken {
+		s.token.Expiry = time.Unix(1, 0)
+	}
+	s.mu.Unlock()
+}
+
+// OAuth2 authorizes request with access token given by source. When server answers
+// 401 Unauthorized and source might be invalidated (as RefreshingSource), token is
+// refreshed and request is sent once again.
+func OAuth2(src TokenSource) Middleware {
+	return func(e Endpoint) Endpoint {
+		return EndpointFunc(func(r *http.Request) (*http.Response, error) {
+			t, err := src.Token(r.Context())
+			if err != nil {
+				return nil, err
+			}
+
+			r.Header.Set("Authorization", t.Authorization())
+			res, err := e.Do(r)
+			if res == nil || res.StatusCode != http.StatusUnauthorized {
+				return res, err
+			}
+
+			inv, ok := src.(interface{ Invalidate(Token) })
+			if !ok {
+				return res, err
+			}
+			inv.Invalidate(t)
+
+			nt, terr := src.Token(r.Context())
+			if terr != nil || nt.AccessToken == t.AccessToken {
+				return res, err
+			}
+
+			next, rerr := rewind(r)
+			if rerr != nil {
+				return res, err
+			}
+			drain(res)
+
+			next.Header.Set("Authorization", nt.Authorization())
+			return e.Do(next)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/livechat/gokit/test/is"
+	"github.com/livechat/gokit/web/client"
+)
+
+func TestOAuth2RefreshesOnce(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer s.Close()
+
+	var refreshes int32
+	src := client.NewTokenSource(
+		client.Token{AccessToken: "revoked", RefreshToken: "r1", Expiry: time.Now().Add(time.Hour)},
+		func(ctx context.Context, refresh string) (client.Token, error) {
+			atomic.AddInt32(&refreshes, 1)
+			time.Sleep(10 * time.Millisecond)
+			return client.Token{AccessToken: "fresh", Expiry: time.Now().Add(time.Hour)}, nil
+		},
+	)
+
+	c := client.NewCaller(s.Client(), client.ResponseError(nil), client.OAuth2(src))
+
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = c.Call(client.Option{URL: s.URL})
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		is.Ok(t, err)
+	}
+
+	is.Equal(t, int32(1), atomic.LoadInt32(&refreshes))
+
+	tk, err := src.Token(context.Background())
+	is.Ok(t, err)
+	is.Equal(t, "r1", tk.RefreshToken)
+}
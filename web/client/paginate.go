@@ -0,0 +1,303 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Page is a single page fetched by Pager.
+type Page struct {
+	// Number of page, starting from 1.
+	Number int
+	// Option used to fetch the page.
+	Option Option
+	// Value is decoded response of the page, it has the same type as Option.Response.
+	Value interface{}
+	// Header of http response.
+	Header http.Header
+	// Items is number of items on the page, or -1 when it is not known.
+	Items int
+}
+
+// PageStrategy tells how to move from one page to another.
+type PageStrategy interface {
+	// First prepares option of first page.
+	First(o Option) (Option, error)
+	// Next prepares option of page following prev, false is returned when
+	// there are no more pages.
+	Next(o Option, prev Page) (Option, bool, error)
+}
+
+// Pager is an iterator over paginated resource, pages are fetched lazily by Next.
+//
+//	p := client.Paginate(caller, client.Option{URL: u, Response: &[]tags.Tag{}}, client.PageNumber("page", "limit", 100))
+//	for p.Next(ctx) {
+//		tags := *p.Value().(*[]tags.Tag)
+//	}
+//	if err := p.Err(); err != nil {
+//		...
+//	}
+type Pager struct {
+	// MaxPages stops iteration after given number of pages, when greater than zero.
+	MaxPages int
+	// MaxItems stops iteration after given number of items, when greater than zero.
+	// Slice of last page is truncated to fit in the limit.
+	MaxItems int
+	// Count returns number of items on the page, by default length of slice
+	// pointed by Option.Response is used.
+	Count func(v interface{}) int
+
+	caller   Caller
+	option   Option
+	strategy PageStrategy
+	page     Page
+	items    int
+	done     bool
+	err      error
+}
+
+// Paginate creates Pager, Option.Response has to be a pointer, which type is used
+// to allocate new value for every page.
+func Paginate(c Caller, o Option, s PageStrategy) *Pager {
+	return &Pager{caller: c, option: o, strategy: s, Count: sliceLen}
+}
+
+// Next fetches next page, it returns false when there are no more pages, when
+// limits were reached or when page can not be fetched. Err tells which one was it.
+func (p *Pager) Next(ctx context.Context) bool {
+	if p.done {
+		return false
+	}
+
+	if err := ctx.Err(); err != nil {
+		return p.stop(err)
+	}
+
+	if p.MaxPages > 0 && p.page.Number >= p.MaxPages {
+		return p.stop(nil)
+	}
+	if p.MaxItems > 0 && p.items >= p.MaxItems {
+		return p.stop(nil)
+	}
+
+	var (
+		o   Option
+		ok  = true
+		err error
+	)
+	if p.page.Number == 0 {
+		o, err = p.strategy.First(p.option)
+	} else {
+		o, ok, err = p.strategy.Next(p.page.Option, p.page)
+	}
+	if err != nil || !ok {
+		return p.stop(err)
+	}
+
+	rt := reflect.TypeOf(p.option.Response)
+	if rt == nil || rt.Kind() != reflect.Ptr {
+		return p.stop(errors.New("pagination: Option.Response has to be a pointer"))
+	}
+	o.Response = reflect.New(rt.Elem()).Interface()
+
+	var header http.Header
+	hook := func(_ *http.Request, res *http.Response) { header = res.Header }
+	if err := p.caller.CallContext(withResponseHook(ctx, hook), o); err != nil {
+		return p.stop(err)
+	}
+
+	items := p.Count(o.Response)
+	if p.MaxItems > 0 && items > p.MaxItems-p.items {
+		items = truncate(o.Response, p.MaxItems-p.items)
+	}
+	if items > 0 {
+		p.items += items
+	}
+
+	p.page = Page{
+		Number: p.page.Number + 1,
+		Option: o,
+		Value:  o.Response,
+		Header: header,
+		Items:  items,
+	}
+
+	return true
+}
+
+// Page returns last fetched page.
+func (p *Pager) Page() Page { return p.page }
+
+// Value returns decoded response of last fetched page.
+func (p *Pager) Value() interface{} { return p.page.Value }
+
+// Err returns error which stopped iteration.
+func (p *Pager) Err() error { return p.err }
+
+func (p *Pager) stop(err error) bool {
+	p.done = true
+	p.err = err
+	return false
+}
+
+func sliceLen(v interface{}) int {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return -1
+	}
+	return rv.Len()
+}
+
+// truncate shortens slice pointed by v to n elements, it returns new length.
+func truncate(v interface{}, n int) int {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Slice || !rv.CanSet() {
+		return n
+	}
+
+	rv.Set(rv.Slice(0, n))
+	return n
+}
+
+type pageNumber struct {
+	page, limit string
+	size        int
+}
+
+// PageNumber paginates with page number and page size query parameters, such as
+// ?page=2&limit=100. Iteration stops on page which has less than size items.
+func PageNumber(page, limit string, size int) PageStrategy {
+	return pageNumber{page: page, limit: limit, size: size}
+}
+
+func (s pageNumber) First(o Option) (Option, error) {
+	q := url.Values{}
+	q.Set(s.page, "1")
+	if s.limit != "" && s.size > 0 {
+		q.Set(s.limit, strconv.Itoa(s.size))
+	}
+
+	var err error
+	o.URL, err = withQuery(o.URL, q)
+	return o, err
+}
+
+func (s pageNumber) Next(o Option, prev Page) (Option, bool, error) {
+	if prev.Items <= 0 || (s.size > 0 && prev.Items < s.size) {
+		return o, false, nil
+	}
+
+	var err error
+	o.URL, err = withQuery(o.URL, url.Values{s.page: {strconv.Itoa(prev.Number + 1)}})
+	return o, true, err
+}
+
+type cursor struct {
+	param string
+	next  func(v interface{}) string
+}
+
+// Cursor paginates with token returned by every page. Function next reads the
+// token from decoded page, which is sent as param query parameter to get next page.
+// Iteration stops when next returns empty token.
+func Cursor(param string, next func(v interface{}) string) PageStrategy {
+	return cursor{param: param, next: next}
+}
+
+func (s cursor) First(o Option) (Option, error) { return o, nil }
+
+func (s cursor) Next(o Option, prev Page) (Option, bool, error) {
+	token := s.next(prev.Value)
+	if token == "" {
+		return o, false, nil
+	}
+
+	var err error
+	o.URL, err = withQuery(o.URL, url.Values{s.param: {token}})
+	return o, true, err
+}
+
+type link struct{}
+
+// Link paginates with RFC 5988 Link response header, url of rel="next" is
+// used to fetch next page until there is none.
+func Link() PageStrategy { return link{} }
+
+func (link) First(o Option) (Option, error) { return o, nil }
+
+func (link) Next(o Option, prev Page) (Option, bool, error) {
+	next := linkRel(prev.Header, "next")
+	if next == "" {
+		return o, false, nil
+	}
+
+	base, err := url.Parse(o.URL)
+	if err != nil {
+		return o, false, err
+	}
+
+	u, err := base.Parse(next)
+	if err != nil {
+		return o, false, err
+	}
+
+	o.URL = u.String()
+	return o, true, nil
+}
+
+// linkRel finds url of given relation in Link headers.
+func linkRel(h http.Header, rel string) string {
+	for _, v := range h.Values("Link") {
+		for _, l := range strings.Split(v, ",") {
+			parts := strings.Split(l, ";")
+			u := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(u, "<") || !strings.HasSuffix(u, ">") {
+				continue
+			}
+
+			for _, p := range parts[1:] {
+				kv := strings.SplitN(strings.TrimSpace(p), "=", 2)
+				if len(kv) != 2 || !strings.EqualFold(kv[0], "rel") {
+					continue
+				}
+
+				for _, r := range strings.Fields(strings.Trim(kv[1], `"`)) {
+					if strings.EqualFold(r, rel) {
+						return u[1 : len(u)-1]
+					}
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+// withQuery sets query parameters of raw url.
+func withQuery(raw string, q url.Values) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw, err
+	}
+
+	v := u.Query()
+	for k := range q {
+		v[k] = q[k]
+	}
+	u.RawQuery = v.Encode()
+
+	return u.String(), nil
+}
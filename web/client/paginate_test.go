@@ -0,0 +1,79 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/livechat/gokit/test/is"
+	"github.com/livechat/gokit/web/client"
+)
+
+// tagsServer serves 7 tags, paginated with page and limit parameters.
+func tagsServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+		var tags []string
+		for i := (page - 1) * limit; i < page*limit && i < 7; i++ {
+			tags = append(tags, fmt.Sprintf("tag-%d", i))
+		}
+
+		if page*limit < 7 {
+			w.Header().Set("Link", fmt.Sprintf(`</tags?page=%d&limit=%d>; rel="next"`, page+1, limit))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(tags)
+	}))
+}
+
+func TestPaginatePageNumber(t *testing.T) {
+	s := tagsServer()
+	defer s.Close()
+
+	c := client.NewCaller(s.Client(), client.JSONResponse())
+	p := client.Paginate(c, client.Option{URL: s.URL + "/tags", Response: &[]string{}}, client.PageNumber("page", "limit", 3))
+
+	var all []string
+	for p.Next(context.Background()) {
+		all = append(all, *p.Value().(*[]string)...)
+	}
+	is.Ok(t, p.Err())
+	is.Equal(t, 7, len(all))
+	is.Equal(t, 3, p.Page().Number)
+}
+
+func TestPaginateLinkWithLimit(t *testing.T) {
+	s := tagsServer()
+	defer s.Close()
+
+	c := client.NewCaller(s.Client(), client.JSONResponse())
+	p := client.Paginate(c, client.Option{URL: s.URL + "/tags?page=1&limit=2", Response: &[]string{}}, client.Link())
+	p.MaxItems = 5
+
+	var all []string
+	for p.Next(context.Background()) {
+		all = append(all, *p.Value().(*[]string)...)
+	}
+	is.Ok(t, p.Err())
+	is.Equal(t, []string{"tag-0", "tag-1", "tag-2", "tag-3", "tag-4"}, all)
+}
+
+func TestPaginateCancelled(t *testing.T) {
+	s := tagsServer()
+	defer s.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	c := client.NewCaller(s.Client(), client.JSONResponse())
+	p := client.Paginate(c, client.Option{URL: s.URL + "/tags", Response: &[]string{}}, client.PageNumber("page", "limit", 3))
+
+	is.True(t, !p.Next(ctx), "no page expected")
+	is.Equal(t, context.Canceled, p.Err())
+}
@@ -0,0 +1,130 @@
+package client
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EncodeQuery turns v into url query. It accepts url.Values, map[string]string,
+// map[string][]string or struct (pointer) which exported fields are encoded
+// under name given by `query:"name"` tag, or by field name when tag is not set.
+// Fields tagged with "-" are skipped and the ones with omitempty option are
+// skipped when they have zero value. Slices are encoded as repeated parameters,
+// time.Time as RFC3339 and encoding.TextMarshaler with its MarshalText.
+func EncodeQuery(v interface{}) (url.Values, error) {
+	switch q := v.(type) {
+	case nil:
+		return url.Values{}, nil
+	case url.Values:
+		return q, nil
+	case map[string][]string:
+		return url.Values(q), nil
+	case map[string]string:
+		o := url.Values{}
+		for k, v := range q {
+			o.Set(k, v)
+		}
+		return o, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return url.Values{}, nil
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("client: query of type %T is not supported", v)
+	}
+
+	o := url.Values{}
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		name, opts := f.Name, ""
+		if tag, ok := f.Tag.Lookup("query"); ok {
+			if tag == "-" {
+				continue
+			}
+			if p := strings.Index(tag, ","); p != -1 {
+				tag, opts = tag[:p], tag[p+1:]
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+
+		fv := rv.Field(i)
+		if strings.Contains(opts, "omitempty") && fv.IsZero() {
+			continue
+		}
+
+		if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+			for j := 0; j < fv.Len(); j++ {
+				s, err := queryValue(fv.Index(j))
+				if err != nil {
+					return nil, fmt.Errorf("client: query field %s: %w", f.Name, err)
+				}
+				o.Add(name, s)
+			}
+			continue
+		}
+
+		s, err := queryValue(fv)
+		if err != nil {
+			return nil, fmt.Errorf("client: query field %s: %w", f.Name, err)
+		}
+		o.Set(name, s)
+	}
+
+	return o, nil
+}
+
+func queryValue(v reflect.Value) (string, error) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+
+	switch i := v.Interface().(type) {
+	case time.Time:
+		return i.Format(time.RFC3339), nil
+	case encoding.TextMarshaler:
+		b, err := i.MarshalText()
+		return string(b), err
+	case fmt.Stringer:
+		return i.String(), nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()), nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes()), nil
+		}
+	}
+
+	return "", fmt.Errorf("type %s is not supported", v.Type())
+}
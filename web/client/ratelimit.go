@@ -0,0 +1,228 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimitConfig configures RateLimit, zero values are replaced with defaults.
+type RateLimitConfig struct {
+	// Rate is number of requests per second allowed for single key (default 10).
+	Rate float64
+	// Burst is number of requests which might be sent at once (default 1).
+	Burst int
+	// MinRate is the lowest rate to which limiter shrinks after
+	// server signals exceeded quota (default Rate / 10).
+	MinRate float64
+	// Key groups requests sharing the same bucket (default ByHost).
+	Key func(*http.Request) string
+	// IdleTimeout is time after which bucket of unused key is removed (default 10m).
+	IdleTimeout time.Duration
+}
+
+// ByHost shares rate limit between all requests sent to the same host.
+func ByHost(r *http.Request) string { return r.URL.Host }
+
+// ByToken shares rate limit between requests with the same Authorization header,
+// so Authorization middleware has to be applied before RateLimit is reached.
+// Key is a hash of the header, so credentials are not kept by limiter.
+func ByToken(r *http.Request) string {
+	h := sha256.Sum256([]byte(r.Header.Get("Authorization")))
+	return hex.EncodeToString(h[:16])
+}
+
+// RateLimit is a token bucket limiter, it blocks request until token is available
+// or request context is done. Rate is shrunk after 429 Too Many Requests response
+// or when X-RateLimit-Remaining and X-RateLimit-Reset headers show quota is
+// running out, then it slowly grows back to configured value.
+func RateLimit(c RateLimitConfig) Middleware {
+	if c.Rate <= 0 {
+		c.Rate = 10
+	}
+	if c.Burst <= 0 {
+		c.Burst = 1
+	}
+	if c.MinRate <= 0 || c.MinRate > c.Rate {
+		c.MinRate = c.Rate / 10
+	}
+	if c.Key == nil {
+		c.Key = ByHost
+	}
+	if c.IdleTimeout <= 0 {
+		c.IdleTimeout = 10 * time.Minute
+	}
+
+	var (
+		mu      sync.Mutex
+		buckets = map[string]*bucket{}
+		swept   time.Time
+	)
+
+	get := func(key string) *bucket {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// idle buckets are removed, so limiter does not grow with every key seen
+		now := time.Now()
+		if now.Sub(swept) >= c.IdleTimeout {
+			for k, b := range buckets {
+				if b.idle(now, c.IdleTimeout) {
+					delete(buckets, k)
+				}
+			}
+			swept = now
+		}
+
+		b, ok := buckets[key]
+		if !ok {
+			b = &bucket{
+				rate:   c.Rate,
+				max:    c.Rate,
+				min:    c.MinRate,
+				burst:  float64(c.Burst),
+				tokens: float64(c.Burst),
+				last:   now,
+			}
+			buckets[key] = b
+		}
+		b.used = now
+		return b
+	}
+
+	return func(e Endpoint) Endpoint {
+		return EndpointFunc(func(r *http.Request) (*http.Response, error) {
+			b := get(c.Key(r))
+
+			if wait := b.reserve(time.Now()); wait > 0 {
+				t := time.NewTimer(wait)
+				select {
+				case <-r.Context().Done():
+					t.Stop()
+					b.cancel()
+					return nil, r.Context().Err()
+				case <-t.C:
+				}
+			}
+
+			res, err := e.Do(r)
+			if res != nil {
+				b.adapt(res, time.Now())
+			}
+
+			return res, err
+		})
+	}
+}
+
+type bucket struct {
+	mu       sync.Mutex
+	rate     float64
+	max, min float64
+	burst    float64
+	tokens   float64
+	last     time.Time
+	blocked  time.Time
+	// used is time of last request, it's guarded by mutex of buckets map
+	used time.Time
+}
+
+// idle tells if bucket was not used for d and does not block requests.
+func (b *bucket) idle(now time.Time, d time.Duration) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return now.Sub(b.used) >= d && !b.blocked.After(now)
+}
+
+// refill adds tokens collected since last call, it has to be called with locked mutex.
+func (b *bucket) refill(now time.Time) {
+	if d := now.Sub(b.last); d > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+d.Seconds()*b.rate)
+		b.last = now
+	}
+}
+
+// reserve takes token from bucket and returns time after which it might be used.
+func (b *bucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(now)
+	b.tokens--
+
+	var wait time.Duration
+	if b.tokens < 0 {
+		wait = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+
+	if d := b.blocked.Sub(now); d > wait {
+		wait = d
+	}
+
+	return wait
+}
+
+// cancel gives back token of request which was not sent.
+func (b *bucket) cancel() {
+	b.mu.Lock()
+	b.tokens = math.Min(b.burst, b.tokens+1)
+	b.mu.Unlock()
+}
+
+// adapt changes rate according to quota information sent by server.
+func (b *bucket) adapt(res *http.Response, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(now)
+
+	if res.StatusCode == http.StatusTooManyRequests {
+		b.rate = math.Max(b.min, b.rate/2)
+		if d, ok := retryAfter(res); ok {
+			b.block(now.Add(d))
+		}
+		return
+	}
+
+	remaining, rerr := strconv.Atoi(res.Header.Get("X-RateLimit-Remaining"))
+	reset, ok := rateLimitReset(res.Header.Get("X-RateLimit-Reset"), now)
+	if rerr != nil || !ok {
+		// nothing is known about quota, go slowly back to configured rate
+		b.rate = math.Min(b.max, b.rate+b.max/20)
+		return
+	}
+
+	if remaining <= 0 {
+		b.block(reset)
+		return
+	}
+
+	if d := reset.Sub(now).Seconds(); d > 0 {
+		b.rate = math.Max(b.min, math.Min(b.max, float64(remaining)/d))
+	}
+}
+
+func (b *bucket) block(until time.Time) {
+	if until.After(b.blocked) {
+		b.blocked = until
+	}
+}
+
+// rateLimitReset parses X-RateLimit-Reset header, which is given as unix
+// timestamp or as number of seconds left to quota reset.
+func rateLimitReset(v string, now time.Time) (time.Time, bool) {
+	s, err := strconv.ParseFloat(v, 64)
+	if err != nil || s < 0 {
+		return time.Time{}, false
+	}
+
+	if s > 1e9 {
+		return time.Unix(0, int64(s*float64(time.Second))), true
+	}
+
+	return now.Add(time.Duration(s * float64(time.Second))), true
+}
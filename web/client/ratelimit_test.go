@@ -0,0 +1,116 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/livechat/gokit/test/is"
+	"github.com/livechat/gokit/web/client"
+)
+
+func TestRateLimitWaits(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer s.Close()
+
+	c := client.NewCaller(s.Client(), client.RateLimit(client.RateLimitConfig{Rate: 20, Burst: 1}))
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		is.Ok(t, c.Call(client.Option{URL: s.URL}))
+	}
+	is.True(t, time.Since(start) >= 90*time.Millisecond, "requests should wait for tokens, took %s", time.Since(start))
+}
+
+func TestRateLimitIdle(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer s.Close()
+
+	c := client.NewCaller(s.Client(),
+		client.RateLimit(client.RateLimitConfig{Rate: 1, Burst: 1, Key: client.ByToken, IdleTimeout: 20 * time.Millisecond}),
+		client.Authorization("secret"),
+	)
+	is.Ok(t, c.Call(client.Option{URL: s.URL}))
+
+	// bucket of idle key is removed, next request gets a full one
+	time.Sleep(30 * time.Millisecond)
+	start := time.Now()
+	is.Ok(t, c.Call(client.Option{URL: s.URL}))
+	is.True(t, time.Since(start) < 500*time.Millisecond, "idle bucket should be removed, took %s", time.Since(start))
+
+	r, _ := http.NewRequest(http.MethodGet, s.URL, nil)
+	r.Header.Set("Authorization", "Bearer secret")
+	is.True(t, !strings.Contains(client.ByToken(r), "secret"), "token should not be kept as a key")
+}
+
+func TestRateLimitCancel(t *testing.T) {
+	var calls int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer s.Close()
+
+	c := client.NewCaller(s.Client(), client.RateLimit(client.RateLimitConfig{Rate: 1, Burst: 1}))
+	is.Ok(t, c.Call(client.Option{URL: s.URL}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := c.CallContext(ctx, client.Option{URL: s.URL})
+	is.True(t, errors.Is(err, context.DeadlineExceeded), "deadline exceeded expected, got %v", err)
+	is.True(t, time.Since(start) < 500*time.Millisecond, "waiting should stop with context")
+	is.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestRateLimitTooManyRequests(t *testing.T) {
+	var calls int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer s.Close()
+
+	c := client.NewCaller(s.Client(), client.RateLimit(client.RateLimitConfig{Rate: 20, Burst: 1}))
+	is.Ok(t, c.Call(client.Option{URL: s.URL}))
+
+	// rate is halved to 10 per second, so next token comes after 100ms instead of 50ms
+	start := time.Now()
+	is.Ok(t, c.Call(client.Option{URL: s.URL}))
+	is.True(t, time.Since(start) >= 80*time.Millisecond, "rate should shrink after 429, waited %s", time.Since(start))
+}
+
+func TestRateLimitRemaining(t *testing.T) {
+	for name, reset := range map[string]func() string{
+		"seconds": func() string { return "0.2" },
+		"unix": func() string {
+			return strconv.FormatFloat(float64(time.Now().Add(200*time.Millisecond).UnixNano())/1e9, 'f', 3, 64)
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var calls int32
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&calls, 1) == 1 {
+					w.Header().Set("X-RateLimit-Remaining", "0")
+					w.Header().Set("X-RateLimit-Reset", reset())
+				}
+			}))
+			defer s.Close()
+
+			c := client.NewCaller(s.Client(), client.RateLimit(client.RateLimitConfig{Rate: 100, Burst: 10}))
+			is.Ok(t, c.Call(client.Option{URL: s.URL}))
+
+			// quota is used up, so requests wait until it's reset
+			start := time.Now()
+			is.Ok(t, c.Call(client.Option{URL: s.URL}))
+			is.True(t, time.Since(start) >= 150*time.Millisecond, "request should wait for reset, waited %s", time.Since(start))
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// RecorderMode tells Recorder if it talks to real Endpoint or to cassette file.
+type RecorderMode int
+
+const (
+	// ReplayMode serves responses stored in cassette, Endpoint is never called.
+	ReplayMode RecorderMode = iota
+	// RecordMode calls Endpoint and stores every exchange in cassette.
+	RecordMode
+	// PassthroughMode calls Endpoint and ignores cassette.
+	PassthroughMode
+)
+
+// ErrInteractionNotFound is returned in ReplayMode when cassette has no matching exchange.
+var ErrInteractionNotFound = errors.New("recorder: interaction not found in cassette")
+
+// Cassette is a file with recorded http exchanges.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a single recorded http exchange.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Matcher compares incoming request with recorded one, both are already redacted.
+type Matcher func(in, recorded RecordedRequest) bool
+
+// MatchMethod matches requests with the same method.
+func MatchMethod(in, recorded RecordedRequest) bool { return in.Method == recorded.Method }
+
+// MatchURL matches requests with the same url.
+func MatchURL(in, recorded RecordedRequest) bool { return in.URL == recorded.URL }
+
+// MatchBody matches requests with the same body.
+func MatchBody(in, recorded RecordedRequest) bool { return in.Body == recorded.Body }
+
+// MatchAll matches requests for which all of matchers agree.
+func MatchAll(ms ...Matcher) Matcher {
+	return func(in, recorded RecordedRequest) bool {
+		for _, m := range ms {
+			if !m(in, recorded) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Recorder is an Endpoint which records exchanges with real Endpoint into cassette
+// file and replays them later, so services might be tested without network.
+// Secrets are redacted before anything is written to disk.
+type Recorder struct {
+	Mode     RecorderMode
+	Path     string
+	Endpoint Endpoint
+	// Match finds recorded exchange for request (default method, url and body).
+	Match Matcher
+	// Redactor masks secrets of recorded exchanges (default DefaultRedactor).
+	Redactor Redactor
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     map[int]bool
+}
+
+// NewRecorder creates Recorder of cassette at path, e is called in record and passthrough modes.
+func NewRecorder(path string, mode RecorderMode, e Endpoint) *Recorder {
+	return &Recorder{
+		Mode:     mode,
+		Path:     path,
+		Endpoint: e,
+		Match:    MatchAll(MatchMethod, MatchURL, MatchBody),
+		Redactor: DefaultRedactor,
+	}
+}
+
+func (c *Recorder) Do(r *http.Request) (*http.Response, error) {
+	switch c.Mode {
+	case PassthroughMode:
+		return c.Endpoint.Do(r)
+	case RecordMode:
+		return c.record(r)
+	}
+	return c.replay(r)
+}
+
+func (c *Recorder) replay(r *http.Request) (*http.Response, error) {
+	in, err := c.request(r)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+
+	// every recorded exchange is served once, the last matching one
+	// is repeated when all of them were used
+	found := -1
+	for i, x := range c.cassette.Interactions {
+		if !c.Match(in, x.Request) {
+			continue
+		}
+		found = i
+		if !c.used[i] {
+			break
+		}
+	}
+
+	if found == -1 {
+		return nil, fmt.Errorf("%w: [%s] %s", ErrInteractionNotFound, in.Method, in.URL)
+	}
+	c.used[found] = true
+
+	x := c.cassette.Interactions[found].Response
+	return newResponse(r, x.StatusCode, x.Header, []byte(x.Body)), nil
+}
+
+func (c *Recorder) record(r *http.Request) (*http.Response, error) {
+	in, err := c.request(r)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.Endpoint.Do(r)
+	if err != nil {
+		return res, err
+	}
+
+	body, err := capture(r, res)
+	if err != nil {
+		return res, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cassette == nil {
+		c.cassette = &Cassette{}
+	}
+	c.cassette.Interactions = append(c.cassette.Interactions, Interaction{
+		Request: in,
+		Response: RecordedResponse{
+			StatusCode: res.StatusCode,
+			Header:     c.Redactor.Header(res.Header),
+			Body:       string(c.Redactor.Body(res.Header.Get("Content-Type"), body)),
+		},
+	})
+
+	return res, c.save()
+}
+
+// request makes redacted snapshot of request, body is restored to be read again.
+func (c *Recorder) request(r *http.Request) (RecordedRequest, error) {
+	var body []byte
+	if r.Body != nil && r.Body != http.NoBody {
+		var err error
+		if body, err = ioutil.ReadAll(r.Body); err != nil {
+			return RecordedRequest{}, err
+		}
+		r.Body.Close()
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	return RecordedRequest{
+		Method: r.Method,
+		URL:    c.Redactor.URL(r.URL),
+		Header: c.Redactor.Header(r.Header),
+		Body:   string(c.Redactor.Body(r.Header.Get("Content-Type"), body)),
+	}, nil
+}
+
+// load reads cassette file, it has to be called with locked mutex.
+func (c *Recorder) load() error {
+	if c.used == nil {
+		c.used = map[int]bool{}
+	}
+
+	if c.cassette != nil {
+		return nil
+	}
+
+	b, err := ioutil.ReadFile(c.Path)
+	if err != nil {
+		return err
+	}
+
+	var cs Cassette
+	if err := json.Unmarshal(b, &cs); err != nil {
+		return fmt.Errorf("recorder: %s: %w", c.Path, err)
+	}
+
+	c.cassette = &cs
+	return nil
+}
+
+// save writes cassette file, it has to be called with locked mutex.
+func (c *Recorder) save() error {
+	b, err := json.MarshalIndent(c.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.Path), 0755); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(c.Path, b, 0644)
+}
+
+// newResponse creates in-memory response of request r.
+func newResponse(r *http.Request, status int, h http.Header, body []byte) *http.Response {
+	if h == nil {
+		h = http.Header{}
+	}
+
+	return &http.Response{
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        h.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       r,
+	}
+}
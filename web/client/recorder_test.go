@@ -0,0 +1,53 @@
+package client_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/livechat/gokit/test/is"
+	"github.com/livechat/gokit/web/client"
+)
+
+func TestRecorderRecordAndReplay(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"client_id":"abc","secret":"top-secret"}`))
+	}))
+
+	path := filepath.Join(t.TempDir(), "sso", "clients.json")
+	call := func(c client.Caller) (map[string]string, error) {
+		var out map[string]string
+		return out, c.Call(client.Option{
+			URL:      s.URL + "/client?password=qwerty",
+			Method:   http.MethodPost,
+			Request:  map[string]string{"name": "app", "secret": "my-cool-password"},
+			Response: &out,
+		})
+	}
+	middlewares := []client.Middleware{client.JSONRequest(), client.JSONResponse(), client.Authorization("Bearer fra:token")}
+
+	rec := client.NewRecorder(path, client.RecordMode, s.Client())
+	out, err := call(client.NewCaller(rec, middlewares...))
+	is.Ok(t, err)
+	is.Equal(t, "top-secret", out["secret"])
+
+	b, err := ioutil.ReadFile(path)
+	is.Ok(t, err)
+	for _, secret := range []string{"fra:token", "qwerty", "my-cool-password", "top-secret"} {
+		is.True(t, !strings.Contains(string(b), secret), "%s is not redacted:\n%s", secret, b)
+	}
+
+	s.Close()
+
+	play := client.NewRecorder(path, client.ReplayMode, nil)
+	out, err = call(client.NewCaller(play, middlewares...))
+	is.Ok(t, err)
+	is.Equal(t, "abc", out["client_id"])
+
+	err = client.NewCaller(play).Call(client.Option{URL: s.URL + "/other"})
+	is.Err(t, err, "interaction not found")
+}
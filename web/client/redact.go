@@ -0,0 +1,158 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Redactor masks secrets found in headers, query parameters and bodies. Names
+// are matched case insensitively.
+type Redactor struct {
+	// Headers which values are masked.
+	Headers []string
+	// Query parameters which values are masked.
+	Query []string
+	// Fields of json objects and url encoded forms which values are masked.
+	Fields []string
+	// Mask replaces secret values.
+	Mask string
+}
+
+// DefaultRedactor masks credentials used by LiveChat APIs.
+var DefaultRedactor = Redactor{
+	Headers: []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"},
+	Query:   []string{"password", "token", "access_token", "refresh_token", "client_secret", "api_key"},
+	Fields: []string{"password", "secret", "client_secret", "token", "access_token", "refresh_token",
+		"api_key"},
+	Mask: "[REDACTED]",
+}
+
+func (r Redactor) mask() string {
+	if r.Mask == "" {
+		return "[REDACTED]"
+	}
+	return r.Mask
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Header returns copy of h with masked values.
+func (r Redactor) Header(h http.Header) http.Header {
+	c := h.Clone()
+	for k, v := range c {
+		if contains(r.Headers, k) {
+			for i := range v {
+				v[i] = r.mask()
+			}
+		}
+	}
+	return c
+}
+
+// URL returns u with masked query parameters and user password.
+func (r Redactor) URL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+
+	c := *u
+	if _, ok := c.User.Password(); ok {
+		c.User = url.UserPassword(c.User.Username(), r.mask())
+	}
+
+	if c.RawQuery != "" {
+		q := c.Query()
+		redacted := false
+		for k, v := range q {
+			if contains(r.Query, k) {
+				for i := range v {
+					v[i] = r.mask()
+				}
+				redacted = true
+			}
+		}
+		if redacted {
+			c.RawQuery = q.Encode()
+		}
+	}
+
+	return c.String()
+}
+
+// Body masks fields of json or url encoded form body, other content is
+// returned unchanged.
+func (r Redactor) Body(contentType string, b []byte) []byte {
+	if len(b) == 0 {
+		return b
+	}
+
+	if strings.Contains(contentType, "x-www-form-urlencoded") {
+		q, err := url.ParseQuery(string(b))
+		if err != nil {
+			return b
+		}
+		for k, v := range q {
+			if contains(r.Fields, k) {
+				for i := range v {
+					v[i] = r.mask()
+				}
+			}
+		}
+		return []byte(q.Encode())
+	}
+
+	if contentType != "" && !strings.Contains(contentType, "json") {
+		return b
+	}
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return b
+	}
+
+	if !r.walk(v) {
+		return b
+	}
+
+	o, err := json.Marshal(v)
+	if err != nil {
+		return b
+	}
+	return o
+}
+
+// walk masks json values in place, it reports if anything was masked.
+func (r Redactor) walk(v interface{}) bool {
+	masked := false
+
+	switch o := v.(type) {
+	case map[string]interface{}:
+		for k, e := range o {
+			if contains(r.Fields, k) {
+				o[k] = r.mask()
+				masked = true
+				continue
+			}
+			masked = r.walk(e) || masked
+		}
+	case []interface{}:
+		for _, e := range o {
+			masked = r.walk(e) || masked
+		}
+	}
+
+	return masked
+}
@@ -0,0 +1,229 @@
+package client
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy describes when and how often Retry middleware repeats failed request.
+// Zero values are replaced with values of DefaultRetryPolicy.
+type RetryPolicy struct {
+	// Attempts is maximum number of tries, including the first one.
+	Attempts int
+	// MinBackoff is delay before first retry, it is doubled for every
+	// next retry, but never exceeds MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// Jitter from range [0, 1] is a part of backoff delay which is randomized.
+	Jitter float64
+	// Statuses are http response statuses which are retried.
+	Statuses []int
+	// Unsafe allows retrying non idempotent methods such as POST or PATCH.
+	Unsafe bool
+	// MaxRetryAfter is the longest delay requested by Retry-After header which
+	// is waited for, response asking for longer one is returned as is.
+	MaxRetryAfter time.Duration
+}
+
+// DefaultRetryPolicy retries transport errors and temporary server failures.
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts:      3,
+	MinBackoff:    100 * time.Millisecond,
+	MaxBackoff:    5 * time.Second,
+	Jitter:        0.5,
+	MaxRetryAfter: 30 * time.Second,
+	Statuses: []int{
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	},
+}
+
+// Retry repeats request when it fails with transport error or with one of policy
+// statuses. Delay between attempts grows exponentially, unless server tells
+// how long to wait in Retry-After header. Bodies encoded by Request middleware are
+// replayed, so Retry should be placed closer to Endpoint than Request.
+func Retry(p RetryPolicy) Middleware {
+	p = p.withDefaults()
+
+	return func(e Endpoint) Endpoint {
+		return EndpointFunc(func(r *http.Request) (*http.Response, error) {
+			if !p.Unsafe && !isIdempotent(r) {
+				return e.Do(r)
+			}
+
+			req := r
+			for attempt := 1; ; attempt++ {
+				res, err := e.Do(req)
+				if attempt >= p.Attempts || !p.retryable(req, res, err) {
+					return res, err
+				}
+
+				wait := p.backoff(attempt)
+				if d, ok := retryAfter(res); ok {
+					if d > p.MaxRetryAfter {
+						return res, err
+					}
+					wait = d
+				}
+
+				// there is no point in waiting past deadline of the request
+				if dl, ok := r.Context().Deadline(); ok && time.Now().Add(wait).After(dl) {
+					return res, err
+				}
+
+				next, rerr := rewind(r)
+				if rerr != nil {
+					return res, err
+				}
+
+				drain(res)
+
+				t := time.NewTimer(wait)
+				select {
+				case <-r.Context().Done():
+					t.Stop()
+					return nil, r.Context().Err()
+				case <-t.C:
+				}
+
+				req = next
+			}
+		})
+	}
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	d := DefaultRetryPolicy
+	if p.Attempts <= 0 {
+		p.Attempts = d.Attempts
+	}
+	if p.MinBackoff <= 0 {
+		p.MinBackoff = d.MinBackoff
+	}
+	if p.MaxBackoff < p.MinBackoff {
+		p.MaxBackoff = d.MaxBackoff
+		if p.MaxBackoff < p.MinBackoff {
+			p.MaxBackoff = p.MinBackoff
+		}
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		p.Jitter = d.Jitter
+	}
+	if p.Statuses == nil {
+		p.Statuses = d.Statuses
+	}
+	if p.MaxRetryAfter <= 0 {
+		p.MaxRetryAfter = d.MaxRetryAfter
+	}
+
+	return p
+}
+
+func (p RetryPolicy) retryable(r *http.Request, res *http.Response, err error) bool {
+	if r.Context().Err() != nil {
+		return false
+	}
+
+	if res != nil {
+		for _, s := range p.Statuses {
+			if res.StatusCode == s {
+				return true
+			}
+		}
+		return false
+	}
+
+	var uerr *url.Error
+	return err != nil && errors.As(err, &uerr)
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.MinBackoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+
+	j := time.Duration(float64(d) * p.Jitter)
+	if j <= 0 {
+		return d
+	}
+
+	return d - j + time.Duration(rand.Int63n(int64(j)+1))
+}
+
+// retryAfter reads delay from Retry-After header, given as seconds or http date.
+func retryAfter(res *http.Response) (time.Duration, bool) {
+	if res == nil {
+		return 0, false
+	}
+
+	v := res.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+
+	if s, err := strconv.Atoi(v); err == nil && s >= 0 {
+		return time.Duration(s) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
+
+// isIdempotent tells if request might be sent more than once without side effects.
+func isIdempotent(r *http.Request) bool {
+	switch r.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return r.Header.Get("Idempotency-Key") != ""
+}
+
+// rewind prepares copy of request with fresh body, which is ready to be sent again.
+func rewind(r *http.Request) (*http.Request, error) {
+	c := r.Clone(r.Context())
+	if r.Body == nil || r.Body == http.NoBody {
+		return c, nil
+	}
+
+	if r.GetBody == nil {
+		return nil, errors.New("request body can not be replayed")
+	}
+
+	b, err := r.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	c.Body = b
+
+	return c, nil
+}
+
+// drain reads rest of response body and closes it, so connection might be reused.
+func drain(res *http.Response) {
+	if res == nil || res.Body == nil {
+		return
+	}
+
+	io.CopyN(ioutil.Discard, res.Body, 4<<10)
+	res.Body.Close()
+}
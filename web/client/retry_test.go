@@ -0,0 +1,82 @@
+package client_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/livechat/gokit/test/is"
+	"github.com/livechat/gokit/web/client"
+)
+
+func TestRetryReplaysBody(t *testing.T) {
+	var calls int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		if string(b) != "{\"id\":1}\n" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer s.Close()
+
+	c := client.NewCaller(s.Client(),
+		client.Retry(client.RetryPolicy{Attempts: 3, MinBackoff: time.Millisecond, Unsafe: true}),
+		client.ResponseError(nil),
+		client.JSONRequest(),
+		client.JSONResponse(),
+	)
+
+	var out struct{ OK bool }
+	is.Ok(t, c.Call(client.Option{URL: s.URL, Method: http.MethodPost, Request: map[string]int{"id": 1}, Response: &out}))
+	is.True(t, out.OK, "response from third attempt expected")
+	is.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestRetrySkipsUnsafeMethods(t *testing.T) {
+	var calls int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer s.Close()
+
+	c := client.NewCaller(s.Client(),
+		client.Retry(client.RetryPolicy{MinBackoff: time.Millisecond}),
+		client.ResponseError(nil),
+	)
+
+	is.Err(t, c.Call(client.Option{URL: s.URL, Method: http.MethodPost}), "bad gateway")
+	is.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	is.Err(t, c.Call(client.Option{URL: s.URL, Method: http.MethodGet}), "bad gateway")
+	is.Equal(t, int32(4), atomic.LoadInt32(&calls))
+}
+
+func TestRetryAfterLimit(t *testing.T) {
+	var calls int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "86400")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer s.Close()
+
+	c := client.NewCaller(s.Client(), client.ResponseError(nil), client.Retry(client.RetryPolicy{}))
+
+	start := time.Now()
+	err := c.Call(client.Option{URL: s.URL})
+	is.True(t, client.IsTooManyRequests(err), "too many requests expected, got %v", err)
+	is.True(t, time.Since(start) < time.Second, "long Retry-After should not be waited for")
+	is.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,222 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+)
+
+// ErrStop might be returned by record callback to stop streaming without error.
+var ErrStop = errors.New("stop streaming")
+
+// JSONStreamResponse decodes response without loading it whole into memory. Elements
+// of top level json array, or every value of newline delimited json, are decoded one by
+// one and passed to callback func(T) error or sent to channel of T given as Option.Response.
+// Channel is not closed, as it might be shared by several calls, its owner closes
+// it after the calls return. Sending is interrupted when request context is done.
+// Unsuccessful response is not streamed, *HTTPError is returned instead.
+func JSONStreamResponse() Middleware {
+	return func(e Endpoint) Endpoint {
+		return EndpointFunc(func(r *http.Request) (*http.Response, error) {
+			output := responseValue(r)
+			if output == nil {
+				return e.Do(r)
+			}
+
+			if r.Header.Get("Accept") == "" {
+				r.Header.Set("Accept", "application/json, application/x-ndjson;q=0.9")
+			}
+
+			res, err := e.Do(r)
+			if err != nil {
+				return res, err
+			}
+
+			if res.StatusCode < 200 || res.StatusCode >= 300 {
+				b, _ := capture(r, res)
+				return res, newHTTPError(r, res, b)
+			}
+
+			if err := streamJSON(r.Context(), res.Body, sendFunc(r, output)); err != nil {
+				if cerr := r.Context().Err(); cerr != nil {
+					return res, cerr
+				}
+				return res, err
+			}
+
+			return res, nil
+		})
+	}
+}
+
+// JsonStreamDecode is a Decoder of json array or newline delimited json, which
+// passes decoded elements one by one to callback, channel or slice (v).
+func JsonStreamDecode(r io.Reader, v interface{}) error {
+	return streamJSON(context.Background(), r, v)
+}
+
+func streamJSON(ctx context.Context, r io.Reader, v interface{}) error {
+	s, err := newSink(v, ctx.Done())
+	if err != nil {
+		return fmt.Errorf("json: type %T: %w", v, err)
+	}
+	defer s.close()
+
+	br := bufio.NewReader(r)
+	array, err := startsArray(br)
+	if err == io.EOF {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	d := json.NewDecoder(br)
+	if array {
+		if _, err := d.Token(); err != nil {
+			return err
+		}
+	}
+
+	for i := 0; ; i++ {
+		if array && !d.More() {
+			_, err := d.Token()
+			return err
+		}
+
+		e := reflect.New(s.elem)
+		if err := d.Decode(e.Interface()); err == io.EOF && !array {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("json: element %d: %w", i, err)
+		}
+
+		if err := s.put(e.Elem()); err != nil {
+			return stopped(err)
+		}
+	}
+}
+
+// startsArray peeks first non white space character of json document.
+func startsArray(r *bufio.Reader) (bool, error) {
+	for {
+		b, err := r.Peek(1)
+		if err != nil {
+			return false, err
+		}
+
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			r.ReadByte()
+		default:
+			return b[0] == '[', nil
+		}
+	}
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// sendFunc turns channel given as Option.Response into callback which sends to it,
+// so the channel is not closed by decoder of single request, as request might be
+// sent again by Retry or OAuth2, and channel might be shared by several calls.
+func sendFunc(r *http.Request, v interface{}) interface{} {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Chan || rv.Type().ChanDir()&reflect.SendDir == 0 {
+		return v
+	}
+
+	ctx := r.Context()
+	ft := reflect.FuncOf([]reflect.Type{rv.Type().Elem()}, []reflect.Type{errorType}, false)
+
+	return reflect.MakeFunc(ft, func(in []reflect.Value) []reflect.Value {
+		cases := []reflect.SelectCase{
+			{Dir: reflect.SelectSend, Chan: rv, Send: in[0]},
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+		}
+
+		err := reflect.Zero(errorType)
+		if i, _, _ := reflect.Select(cases); i != 0 {
+			err = reflect.ValueOf(ctx.Err())
+		}
+		return []reflect.Value{err}
+	}).Interface()
+}
+
+// stopped hides ErrStop returned by record callback.
+func stopped(err error) error {
+	if errors.Is(err, ErrStop) {
+		return nil
+	}
+	return err
+}
+
+// sink delivers decoded records one by one into v, which might be a callback
+// func(T) error, a channel of T or a pointer to slice of T.
+type sink struct {
+	elem  reflect.Type
+	put   func(reflect.Value) error
+	close func()
+}
+
+// errSink is returned when v can not receive records.
+var errSink = errors.New("expects func(T) error, chan T or pointer to slice")
+
+// newSink creates sink for v, sending to channel is interrupted when done is closed.
+func newSink(v interface{}, done <-chan struct{}) (*sink, error) {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return nil, errSink
+	}
+
+	rt := rv.Type()
+	switch {
+	case rt.Kind() == reflect.Func:
+		if rt.NumIn() != 1 || rt.NumOut() != 1 || rt.Out(0) != errorType {
+			return nil, fmt.Errorf("%s %w", rt, errSink)
+		}
+
+		return &sink{
+			elem: rt.In(0),
+			put: func(e reflect.Value) error {
+				err, _ := rv.Call([]reflect.Value{e})[0].Interface().(error)
+				return err
+			},
+			close: func() {},
+		}, nil
+
+	case rt.Kind() == reflect.Chan && rt.ChanDir()&reflect.SendDir != 0:
+		cases := []reflect.SelectCase{{Dir: reflect.SelectSend, Chan: rv}}
+		if done != nil {
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(done)})
+		}
+
+		return &sink{
+			elem: rt.Elem(),
+			put: func(e reflect.Value) error {
+				cases[0].Send = e
+				if i, _, _ := reflect.Select(cases); i != 0 {
+					return context.Canceled
+				}
+				return nil
+			},
+			close: func() { rv.Close() },
+		}, nil
+
+	case rt.Kind() == reflect.Ptr && rt.Elem().Kind() == reflect.Slice:
+		s := rv.Elem()
+		return &sink{
+			elem: rt.Elem().Elem(),
+			put: func(e reflect.Value) error {
+				s.Set(reflect.Append(s, e))
+				return nil
+			},
+			close: func() {},
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%s %w", rt, errSink)
+}
@@ -0,0 +1,144 @@
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/livechat/gokit/test/is"
+	"github.com/livechat/gokit/web/client"
+)
+
+type customer struct {
+	License int `json:"license_id"`
+}
+
+func TestJSONStreamCallback(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(` [{"license_id":1}, {"license_id":2}, {"license_id":3}]`))
+	}))
+	defer s.Close()
+
+	c := client.NewCaller(s.Client(), client.JSONStreamResponse())
+
+	var all []int
+	is.Ok(t, c.Call(client.Option{URL: s.URL, Response: func(c customer) error {
+		all = append(all, c.License)
+		return nil
+	}}))
+	is.Equal(t, []int{1, 2, 3}, all)
+
+	var first []int
+	is.Ok(t, c.Call(client.Option{URL: s.URL, Response: func(c customer) error {
+		first = append(first, c.License)
+		if len(first) == 2 {
+			return client.ErrStop
+		}
+		return nil
+	}}))
+	is.Equal(t, []int{1, 2}, first)
+}
+
+func TestJSONStreamChannel(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Write([]byte("{\"license_id\":1}\n{\"license_id\":2}\n"))
+	}))
+	defer s.Close()
+
+	c := client.NewCaller(s.Client(), client.JSONStreamResponse())
+
+	ch := make(chan customer)
+	errc := make(chan error, 2)
+	go func() {
+		// channel is reused by calls, so it's closed by its owner
+		for i := 0; i < 2; i++ {
+			if err := c.Call(client.Option{URL: s.URL, Response: ch}); err != nil {
+				errc <- err
+			}
+		}
+		close(ch)
+		close(errc)
+	}()
+
+	var all []int
+	for c := range ch {
+		all = append(all, c.License)
+	}
+	is.Ok(t, <-errc)
+	is.Equal(t, []int{1, 2, 1, 2}, all)
+}
+
+func TestJSONStreamChannelBatch(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"license_id":1}, {"license_id":2}]`))
+	}))
+	defer s.Close()
+
+	c := client.NewCaller(s.Client(), client.JSONStreamResponse())
+
+	ch := make(chan customer)
+	errc := make(chan error, 1)
+	go func() {
+		_, err := client.Batch(context.Background(), c, client.BatchConfig{},
+			client.Option{URL: s.URL, Response: ch}, client.Option{URL: s.URL, Response: ch})
+		close(ch)
+		errc <- err
+	}()
+
+	var sum int
+	for c := range ch {
+		sum += c.License
+	}
+	is.Ok(t, <-errc)
+	is.Equal(t, 6, sum)
+}
+
+func TestJSONStreamError(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`[{"license_id":1}]`))
+	}))
+	defer s.Close()
+
+	c := client.NewCaller(s.Client(), client.JSONStreamResponse())
+
+	var called bool
+	err := c.Call(client.Option{URL: s.URL, Response: func(c customer) error {
+		called = true
+		return nil
+	}})
+	is.True(t, client.IsServerError(err), "expected server error, got %v", err)
+	is.True(t, !called, "unsuccessful response should not be streamed")
+}
+
+func TestJSONStreamChannelRetried(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer new" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`[{"license_id":1}, {"license_id":2}]`))
+	}))
+	defer s.Close()
+
+	src := client.NewTokenSource(client.Token{AccessToken: "old", RefreshToken: "r"}, func(ctx context.Context, _ string) (client.Token, error) {
+		return client.Token{AccessToken: "new"}, nil
+	})
+	c := client.NewCaller(s.Client(), client.JSONStreamResponse(), client.OAuth2(src))
+
+	ch := make(chan customer)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- c.Call(client.Option{URL: s.URL, Response: ch})
+		close(ch)
+	}()
+
+	var all []int
+	for c := range ch {
+		all = append(all, c.License)
+	}
+	is.Ok(t, <-errc)
+	is.Equal(t, []int{1, 2}, all)
+}
@@ -0,0 +1,94 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/livechat/gokit/web/trace"
+)
+
+// TraceContext starts client span for every request, as a child of span kept by
+// request context (ie. started by server.With.TraceContext), and propagates it to
+// called service in traceparent header. Response body is not buffered, span is
+// finished with connection timings and body size when body is read to the end or
+// closed, which Caller does when call ends. It should be listed before
+// middlewares which read response body, so the size is measured.
+func TraceContext(exp trace.Exporter) Middleware {
+	nolog := func(string, ...interface{}) {}
+
+	return func(e Endpoint) Endpoint {
+		return EndpointFunc(func(r *http.Request) (*http.Response, error) {
+			ctx, span := trace.Start(r.Context(), r.Method+" "+routeTemplate(r), trace.Client, exp)
+
+			ct := &connTrace{t0: time.Now()}
+			r = r.WithContext(httptrace.WithClientTrace(ctx, ct.hooks(nolog)))
+			r.Header.Set(trace.Header, span.Context().String())
+			if s := span.Context().State; s != "" {
+				r.Header.Set(trace.StateHeader, s)
+			}
+
+			// query is skipped, as it might carry credentials
+			u := *r.URL
+			u.RawQuery, u.User = "", nil
+
+			span.Set("http.method", r.Method)
+			span.Set("http.url", u.String())
+			span.Set("http.host", r.URL.Host)
+
+			res, err := e.Do(r)
+			span.Fail(err)
+			if res == nil {
+				span.Finish()
+				return res, err
+			}
+			span.Set("http.status_code", res.StatusCode)
+
+			finish := func(size int) {
+				if ti := ct.info(r, time.Now(), size); !ti.Timestamp.IsZero() {
+					span.Set("http.dns", ti.DNSLookup.String())
+					span.Set("http.tcp", ti.TCPConnection.String())
+					span.Set("http.tls", ti.TLSHandshake.String())
+					span.Set("http.server_processing", ti.ServerProcessing.String())
+					span.Set("http.content_transfer", ti.ContentTransfer.String())
+					span.Set("http.response_size", ti.ContentSize)
+				}
+				span.Finish()
+			}
+
+			if res.Body == nil || res.Body == http.NoBody {
+				finish(0)
+				return res, err
+			}
+			res.Body = &spanBody{ReadCloser: res.Body, finish: finish}
+
+			return res, err
+		})
+	}
+}
+
+// spanBody counts bytes of response body and finishes span once, when body
+// is read to the end or closed.
+type spanBody struct {
+	io.ReadCloser
+	n      int
+	once   sync.Once
+	finish func(size int)
+}
+
+func (b *spanBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += n
+	if err != nil {
+		b.once.Do(func() { b.finish(b.n) })
+	}
+	return n, err
+}
+
+func (b *spanBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.finish(b.n) })
+	return err
+}
@@ -7,6 +7,8 @@ import (
 
 	"github.com/Rican7/conjson"
 	"github.com/Rican7/conjson/transform"
+	"github.com/gorilla/mux"
+	"github.com/livechat/gokit/web/trace"
 )
 
 var With middleware
@@ -78,6 +80,41 @@ func (middleware) Error(f func(error) (string, int)) Middleware {
 	}
 }
 
+// TraceContext continues trace given by traceparent request header, or starts new
+// one. Server span is kept in request context, so client calls made with that
+// context become its children. Span is passed to exporter after response is written.
+func (middleware) TraceContext(e trace.Exporter) Middleware {
+	return func(n Endpoint) Endpoint {
+		return EndpointFunc(func(r *Request) {
+			ctx := r.Reader.Context()
+			if p, err := trace.Parse(r.Reader.Header.Get(trace.Header)); err == nil {
+				p.State = r.Reader.Header.Get(trace.StateHeader)
+				ctx = trace.WithRemote(ctx, p)
+			}
+
+			route := r.Reader.URL.Path
+			if cr := mux.CurrentRoute(r.Reader); cr != nil {
+				if t, err := cr.GetPathTemplate(); err == nil {
+					route = t
+				}
+			}
+
+			ctx, span := trace.Start(ctx, r.Reader.Method+" "+route, trace.Server, e)
+			span.Set("http.method", r.Reader.Method)
+			span.Set("http.route", route)
+			span.Set("http.target", r.Reader.URL.RequestURI())
+			r.Writer.Header().Set(trace.Header, span.Context().String())
+			r.Reader = r.Reader.WithContext(ctx)
+
+			n.Do(r)
+
+			span.Set("http.status_code", r.Response.Status)
+			span.Fail(r.Response.Error)
+			span.Finish()
+		})
+	}
+}
+
 func (middleware) Test(label string) Middleware {
 	return func(n Endpoint) Endpoint {
 		return EndpointFunc(func(r *Request) {
@@ -34,6 +34,20 @@ func (r *Router) Handle(path string, e EndpointFunc, method string, ms ...Middle
 	return r
 }
 
+// Mount serves standard http.Handler under given path for every method, ie.
+// metrics exposition or profiling handlers.
+func (r *Router) Mount(path string, h http.Handler, ms ...Middleware) *Router {
+	var e Endpoint = EndpointFunc(func(req *Request) { h.ServeHTTP(req.Writer, req.Reader) })
+	for i := len(ms) - 1; i >= 0; i-- {
+		e = ms[i](e)
+	}
+
+	f := func(res http.ResponseWriter, req *http.Request) { e.Do(getRequest(res, req)) }
+	r.mux.Handle(path, http.HandlerFunc(f))
+
+	return r
+}
+
 func (r *Router) Do(req *Request) {}
 
 func (r *Router) ServeHTTP(res http.ResponseWriter, req *http.Request) {
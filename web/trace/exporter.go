@@ -0,0 +1,66 @@
+package trace
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+// Exporter receives spans when they end.
+type Exporter interface {
+	Export(*Span)
+}
+
+// JSON writes every span as a single line json document.
+type JSON struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// Stdout writes spans as json lines into standard output.
+var Stdout = NewJSON(os.Stdout)
+
+func NewJSON(w io.Writer) *JSON { return &JSON{w: w} }
+
+func (j *JSON) Export(s *Span) {
+	s.mu.Lock()
+	b, err := json.Marshal(s)
+	s.mu.Unlock()
+	if err != nil {
+		return
+	}
+
+	j.mu.Lock()
+	j.w.Write(append(b, '\n'))
+	j.mu.Unlock()
+}
+
+// Memory keeps exported spans, it is meant to be used in tests.
+type Memory struct {
+	mu    sync.Mutex
+	spans []*Span
+}
+
+func NewMemory() *Memory { return &Memory{} }
+
+func (m *Memory) Export(s *Span) {
+	m.mu.Lock()
+	m.spans = append(m.spans, s)
+	m.mu.Unlock()
+}
+
+// Spans returns exported spans in order of their end.
+func (m *Memory) Spans() []*Span {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]*Span(nil), m.spans...)
+}
+
+// Reset removes all exported spans.
+func (m *Memory) Reset() {
+	m.mu.Lock()
+	m.spans = nil
+	m.mu.Unlock()
+}
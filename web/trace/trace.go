@@ -0,0 +1,196 @@
+// Package trace implements W3C Trace Context propagation (traceparent header)
+// and spans, which are passed to pluggable Exporter when they end.
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Header is a name of W3C trace context http header.
+	Header = "traceparent"
+	// StateHeader carries vendor specific trace information, it is propagated untouched.
+	StateHeader = "tracestate"
+)
+
+// ErrInvalidParent is returned when traceparent header can not be parsed.
+var ErrInvalidParent = errors.New("trace: invalid traceparent")
+
+// SpanContext identifies span across process boundaries.
+type SpanContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Flags   byte
+	State   string
+}
+
+// Parse reads value of traceparent header, ie.
+// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+func Parse(traceparent string) (SpanContext, error) {
+	var c SpanContext
+
+	p := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(p) < 4 || len(p[0]) != 2 || p[0] == "ff" || (p[0] == "00" && len(p) != 4) {
+		return c, ErrInvalidParent
+	}
+
+	if len(p[1]) != 32 || len(p[2]) != 16 || len(p[3]) != 2 {
+		return c, ErrInvalidParent
+	}
+
+	var flags [1]byte
+	if _, err := hex.Decode(c.TraceID[:], []byte(p[1])); err != nil {
+		return c, ErrInvalidParent
+	}
+	if _, err := hex.Decode(c.SpanID[:], []byte(p[2])); err != nil {
+		return c, ErrInvalidParent
+	}
+	if _, err := hex.Decode(flags[:], []byte(p[3])); err != nil {
+		return c, ErrInvalidParent
+	}
+	c.Flags = flags[0]
+
+	if !c.IsValid() {
+		return c, ErrInvalidParent
+	}
+
+	return c, nil
+}
+
+// String formats span context as traceparent header value.
+func (c SpanContext) String() string {
+	return "00-" + hex.EncodeToString(c.TraceID[:]) + "-" + hex.EncodeToString(c.SpanID[:]) +
+		"-" + hex.EncodeToString([]byte{c.Flags})
+}
+
+// IsValid reports if trace and span identifiers are not zero.
+func (c SpanContext) IsValid() bool {
+	return c.TraceID != [16]byte{} && c.SpanID != [8]byte{}
+}
+
+// Sampled reports if caller recorded its span.
+func (c SpanContext) Sampled() bool { return c.Flags&1 == 1 }
+
+// Span is a single timed operation of a trace.
+type Span struct {
+	Name       string                 `json:"name"`
+	Kind       string                 `json:"kind"`
+	TraceID    string                 `json:"trace_id"`
+	SpanID     string                 `json:"span_id"`
+	ParentID   string                 `json:"parent_id,omitempty"`
+	Start      time.Time              `json:"start"`
+	End        time.Time              `json:"end"`
+	Duration   time.Duration          `json:"duration"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+
+	mu       sync.Mutex
+	context  SpanContext
+	exporter Exporter
+	ended    bool
+}
+
+// Span kinds.
+const (
+	Server = "server"
+	Client = "client"
+)
+
+// Start creates span which is a child of span or remote span context kept by ctx,
+// or a root of new trace when there is none. Returned context keeps the new span.
+func Start(ctx context.Context, name, kind string, e Exporter) (context.Context, *Span) {
+	var parent SpanContext
+	if s := FromContext(ctx); s != nil {
+		parent = s.Context()
+	} else if c, ok := ctx.Value(remoteKey).(SpanContext); ok {
+		parent = c
+	}
+
+	c := SpanContext{Flags: 1, State: parent.State}
+	if parent.IsValid() {
+		c.TraceID = parent.TraceID
+		c.Flags = parent.Flags
+	} else {
+		rand.Read(c.TraceID[:])
+	}
+	rand.Read(c.SpanID[:])
+
+	s := &Span{
+		Name:       name,
+		Kind:       kind,
+		TraceID:    hex.EncodeToString(c.TraceID[:]),
+		SpanID:     hex.EncodeToString(c.SpanID[:]),
+		Start:      time.Now(),
+		Attributes: map[string]interface{}{},
+		context:    c,
+		exporter:   e,
+	}
+	if parent.IsValid() {
+		s.ParentID = hex.EncodeToString(parent.SpanID[:])
+	}
+
+	return context.WithValue(ctx, spanKey, s), s
+}
+
+// Context returns span context, which is propagated to other services.
+func (s *Span) Context() SpanContext { return s.context }
+
+// Set adds attribute to the span.
+func (s *Span) Set(key string, v interface{}) {
+	s.mu.Lock()
+	s.Attributes[key] = v
+	s.mu.Unlock()
+}
+
+// Fail marks span as failed.
+func (s *Span) Fail(err error) {
+	if err == nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.Error = err.Error()
+	s.mu.Unlock()
+}
+
+// Finish ends span and passes it to exporter, only first call has an effect.
+func (s *Span) Finish() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.End = time.Now()
+	s.Duration = s.End.Sub(s.Start)
+	s.mu.Unlock()
+
+	if s.exporter != nil && s.context.Sampled() {
+		s.exporter.Export(s)
+	}
+}
+
+type contextKey int
+
+const (
+	spanKey contextKey = iota
+	remoteKey
+)
+
+// FromContext returns span kept by ctx, or nil.
+func FromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey).(*Span)
+	return s
+}
+
+// WithRemote keeps span context received from other service, so spans
+// started with returned context are its children.
+func WithRemote(ctx context.Context, c SpanContext) context.Context {
+	return context.WithValue(ctx, remoteKey, c)
+}
@@ -0,0 +1,117 @@
+package trace_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/livechat/gokit/test/is"
+	"github.com/livechat/gokit/web/client"
+	"github.com/livechat/gokit/web/server"
+	"github.com/livechat/gokit/web/trace"
+)
+
+func TestParse(t *testing.T) {
+	h := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	c, err := trace.Parse(h)
+	is.Ok(t, err)
+	is.True(t, c.Sampled(), "sampled flag expected")
+	is.Equal(t, h, c.String())
+
+	for _, h := range []string{
+		"",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01",
+	} {
+		_, err := trace.Parse(h)
+		is.Err(t, err, h)
+	}
+}
+
+func TestPropagation(t *testing.T) {
+	spans := trace.NewMemory()
+
+	var received string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get(trace.Header)
+	}))
+	defer upstream.Close()
+
+	c := client.NewCaller(upstream.Client(), client.TraceContext(spans))
+	h := func(r *server.Request) {
+		r.Return(nil, c.CallContext(r.Reader.Context(), client.Option{URL: upstream.URL + "/crm/customers/1"}))
+	}
+
+	s := httptest.NewServer(server.New().Handle("/licenses/{id}", h, "GET", server.With.TraceContext(spans)))
+	defer s.Close()
+
+	req, _ := http.NewRequest("GET", s.URL+"/licenses/1", nil)
+	req.Header.Set(trace.Header, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, err := http.DefaultClient.Do(req)
+	is.Ok(t, err)
+
+	all := spans.Spans()
+	is.Equal(t, 2, len(all))
+
+	cs, ss := all[0], all[1]
+	is.Equal(t, trace.Client, cs.Kind)
+	is.Equal(t, trace.Server, ss.Kind)
+	is.Equal(t, "GET /licenses/{id}", ss.Name)
+	is.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", ss.TraceID)
+	is.Equal(t, "00f067aa0ba902b7", ss.ParentID)
+	is.Equal(t, ss.TraceID, cs.TraceID)
+	is.Equal(t, ss.SpanID, cs.ParentID)
+	is.Equal(t, "00-"+cs.TraceID+"-"+cs.SpanID+"-01", received)
+	is.Equal(t, 200, cs.Attributes["http.status_code"])
+}
+
+func TestClientSpanStreaming(t *testing.T) {
+	spans := trace.NewMemory()
+
+	release := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id":0}`)
+		w.(http.Flusher).Flush()
+
+		// rest of the body is sent after first record is received
+		select {
+		case <-release:
+		case <-time.After(2 * time.Second):
+		}
+		for i := 1; i < 1000; i++ {
+			fmt.Fprintf(w, `,{"id":%d}`, i)
+		}
+		fmt.Fprint(w, `]`)
+	}))
+	defer upstream.Close()
+
+	c := client.NewCaller(upstream.Client(), client.TraceContext(spans), client.JSONStreamResponse())
+
+	type record struct {
+		ID int `json:"id"`
+	}
+	var n int
+	err := c.Call(client.Option{URL: upstream.URL, Response: func(r record) error {
+		if n == 0 {
+			is.Equal(t, 0, len(spans.Spans()))
+			close(release)
+		}
+		n++
+		return nil
+	}})
+	is.Ok(t, err)
+	is.Equal(t, 1000, n)
+
+	all := spans.Spans()
+	is.Equal(t, 1, len(all))
+	size := len(`[{"id":0}]`)
+	for i := 1; i < 1000; i++ {
+		size += len(fmt.Sprintf(`,{"id":%d}`, i))
+	}
+	is.Equal(t, size, all[0].Attributes["http.response_size"])
+	is.Equal(t, 200, all[0].Attributes["http.status_code"])
+}